@@ -37,4 +37,171 @@ class rune(int):pass
 class float32(float):pass
 class float64(float):pass
 class complex64:pass
-class complex128:pass
\ No newline at end of file
+class complex128:pass
+'''
+Concurrency
+
+Goroutines, channels, select and WaitGroup, run with threads so the same
+source works under CPython
+
+go(f, args...)              -> go f(args...)
+ch: Chan[int] = make_chan(n) -> ch := make(chan int, n)
+ch.send(v)                  -> ch <- v
+ch.recv()                   -> <-ch
+i, v = select(recv(a), send(b, x), default()) -> select {}
+defer(f, args...)           -> defer f(args...)
+'''
+
+import collections
+import random
+import sys
+import threading
+import time
+from typing import Generic, TypeVar
+
+T = TypeVar("T")
+
+def go(f, *args):
+    threading.Thread(target=f, args=args, daemon=True).start()
+
+class Chan(Generic[T]):
+    def __init__(self, size=0):
+        self._size = size
+        self._items = collections.deque()
+        self._receivers = 0
+        self._closed = False
+        self._cond = threading.Condition()
+
+    # Room for a value, either in the buffer, or a receiver waiting for it
+    def _room(self):
+        return len(self._items) < self._size or self._receivers > len(self._items)
+
+    def send(self, value):
+        with self._cond:
+            while not self._room() and not self._closed:
+                self._cond.wait()
+            if self._closed:
+                raise RuntimeError("send on closed channel")
+            self._items.append(value)
+            self._cond.notify_all()
+
+    def recv(self):
+        with self._cond:
+            self._receivers += 1
+            self._cond.notify_all()
+            while not self._items and not self._closed:
+                self._cond.wait()
+            self._receivers -= 1
+            if not self._items:
+                return None
+            value = self._items.popleft()
+            self._cond.notify_all()
+            return value
+
+    def close(self):
+        with self._cond:
+            self._closed = True
+            self._cond.notify_all()
+
+    def _try_send(self, value):
+        with self._cond:
+            if self._closed:
+                raise RuntimeError("send on closed channel")
+            if not self._room():
+                return False
+            self._items.append(value)
+            self._cond.notify_all()
+            return True
+
+    def _try_recv(self):
+        with self._cond:
+            if self._items:
+                value = self._items.popleft()
+                self._cond.notify_all()
+                return True, value
+            return self._closed, None
+
+    # Counts a select that's waiting to receive, so an unbuffered send can
+    # hand its value over
+    def _wait_recv(self, n):
+        with self._cond:
+            self._receivers += n
+            self._cond.notify_all()
+
+def make_chan(size=0):
+    return Chan(size)
+
+# Cases of a select
+def recv(ch):
+    return ("recv", ch)
+
+def send(ch, value):
+    return ("send", ch, value)
+
+def default():
+    return ("default",)
+
+# Waits for one of the cases to be ready, returning its index, and the value
+# received if any case receives
+def select(*cases):
+    receives = any(case[0] == "recv" for case in cases)
+    for case in cases:
+        if case[0] == "recv":
+            case[1]._wait_recv(1)
+    try:
+        return _select(cases, receives)
+    finally:
+        for case in cases:
+            if case[0] == "recv":
+                case[1]._wait_recv(-1)
+
+def _select(cases, receives):
+    order = list(range(len(cases)))
+    while True:
+        random.shuffle(order)
+        for i in order:
+            case = cases[i]
+            if case[0] == "recv":
+                ok, value = case[1]._try_recv()
+                if ok:
+                    return i, value
+            elif case[0] == "send" and case[1]._try_send(case[2]):
+                return (i, None) if receives else i
+        for i in order:
+            if cases[i][0] == "default":
+                return (i, None) if receives else i
+        time.sleep(0.001)
+
+class WaitGroup:
+    def __init__(self):
+        self._count = 0
+        self._cond = threading.Condition()
+
+    def add(self, n):
+        with self._cond:
+            self._count += n
+            if self._count < 0:
+                raise RuntimeError("negative WaitGroup counter")
+            self._cond.notify_all()
+
+    def done(self):
+        self.add(-1)
+
+    def wait(self):
+        with self._cond:
+            while self._count > 0:
+                self._cond.wait()
+
+# Calls deferred by each frame, run when the frame returns
+_deferred = {}
+
+def _run_deferred(frame, event, arg):
+    if event == "return" and frame in _deferred:
+        calls = _deferred.pop(frame)
+        for f, args in reversed(calls):
+            f(*args)
+
+def defer(f, *args):
+    frame = sys._getframe(1)
+    _deferred.setdefault(frame, []).append((f, args))
+    sys.setprofile(_run_deferred)
@@ -1,6 +1,25 @@
 package main
 
+import (
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
 type Analyzer struct {
+	returns  []string                  // Return types of the functions being analyzed, innermost last
+	locals   []int                     // Where the variables of each function being analyzed start, innermost last
+	globals  map[string]string         // Module level variables functions can assign to with global, and their types
+	raising  map[string]bool           // Functions that may raise an exception
+	handling int                       // How many except clauses are being analyzed
+	classes  map[string]Class          // Classes that have been defined
+	withs    int                       // How many with statements are being analyzed
+	loops    int                       // How many loops a break could leave
+	modules  map[string]string         // Modules that have been imported, by the name they are used with
+	packages map[string]*types.Package // Go packages that have been imported, by their path
+	top      int                       // The top level structure being analyzed, to say which module an error is in
+	settings Settings
 }
 
 type Variable struct {
@@ -9,47 +28,88 @@ type Variable struct {
 }
 
 type Function struct {
-	name    string
-	params  []string
-	varType string
+	name       string
+	params     []string // Parameter types, in the order Go takes them
+	varType    string
+	names      []string    // Parameter names, for keyword arguments
+	defaults   []Structure // Default values, an empty Structure where there isn't one
+	positional int         // How many parameters can be given by position
+	variadic   bool        // Whether the last parameter collects extra arguments
+	builtin    bool        // Whether the emitter provides the function
+	optional   int         // Parameters at the end that can be left out, for built in methods
+	errors     bool        // Whether a Go function also returns an error, which is raised
+}
+
+type Class struct {
+	name     string
+	fields   []Variable
+	defaults []Structure // The value each field starts with
+	methods  []Function  // Signatures without self
 }
 
 func (a *Analyzer) analyze(s Structure, vars []Variable, funcs []Function) error {
 	//println(s.code)
 
-	if s.code == structureCode["ST_MANIPULATION"] {
-		name := s.children[0].text
-		var valid bool
-		for i := 0; i < len(vars); i++ {
-			valid = false
-			if name == vars[i].name {
-				valid = true
-				break
-			}
+	if s.code == structureCode["ST_MANIPULATION"] && s.children[1].code == structureCode["AUG_ASSIGN"] {
+		err := a.checkAugmented(s, vars, funcs)
+		if err != nil {
+			return err
+		}
+	} else if s.code == structureCode["ST_MANIPULATION"] && s.children[0].code == structureCode["ATTRIBUTE"] {
+		want, err := a.typeChild(s, 0, vars, funcs)
+		if err != nil {
+			return err
+		}
+		t, err := a.typeChild(s, 2, vars, funcs)
+		if err != nil {
+			return err
+		}
+		if !assignable(want, t) {
+			return createError([]string{"analyze.go", "analyze:ST_MANIPULATION"}, "Excpected "+want+" got "+t+" in assignment", s.line)
 		}
+	} else if s.code == structureCode["ST_MANIPULATION"] {
+		variable, valid := findVariable(s.children[0].text, vars)
 		if !valid {
 			return createError([]string{"analyze.go", "analyze:ST_MANIPULATION"}, "An attempt to manipulate an uninitialized variable was made", s.line)
 		}
+		err := a.checkAssignable(variable.name, vars, s.line)
+		if err != nil {
+			return err
+		}
+		settle(s, 2, variable.varType)
+		_, err = a.typeChild(s, 2, vars, funcs)
+		if err != nil {
+			return err
+		}
 	} else if s.code == structureCode["EXPRESSION"] {
-		for i := 0; i < len(s.children); i += 2 {
-			if s.children[i].code != structureCode["IDENTIFIER"] {
-				continue
+		_, err := a.exprType(s, vars, funcs)
+		if err != nil {
+			return err
+		}
+	} else if s.code == structureCode["COMPARISON"] {
+		if len(s.children) == 3 {
+			left, err := a.typeChild(s, 0, vars, funcs)
+			if err != nil {
+				return err
 			}
-
-			name := s.children[i].text
-			var valid bool
-			for j := 0; j < len(vars); j++ {
-				valid = false
-				if name == vars[j].name {
-					valid = true
-					break
-				}
+			right, err := a.typeChild(s, 2, vars, funcs)
+			if err != nil {
+				return err
 			}
-			if !valid {
-				return createError([]string{"analyze.go", "analyze:EXPRESSION"}, "An uninitialized variable was used in an expression", s.line)
+			// Big ints are compared with Cmp, so the emitter is given the
+			// type both sides are compared as
+			t, err := arithmeticType("-", left, right, a.settings, s.line)
+			if a.settings.bigInts && err == nil && (left == "int" || right == "int") {
+				s.children[1].varType = t
+			}
+			// A float constant is worked out in the type of the float it is
+			// compared with
+			if left == "untyped float" && isFloat(right) {
+				s.children[0].varType = defaultType(right)
+			} else if right == "untyped float" && isFloat(left) {
+				s.children[2].varType = defaultType(left)
 			}
 		}
-	} else if s.code == structureCode["COMPARISON"] {
 		for i := 0; i < len(s.children); i += 2 {
 			if s.children[i].code != structureCode["IDENTIFIER"] {
 				continue
@@ -68,116 +128,124 @@ func (a *Analyzer) analyze(s Structure, vars []Variable, funcs []Function) error
 				return createError([]string{"analyze.go", "analyze:COMPARISON"}, "An uninitialized variable was used in a comparison", s.line)
 			}
 		}
-	} else if s.code == structureCode["ST_CALL"] {
-		var fn Function
-
+	} else if s.code == structureCode["ST_IMPORT"] {
+		err := a.importModule(s)
+		if err != nil {
+			return err
+		}
+	} else if s.code == structureCode["ST_CALL"] && s.children[0].varType == "module" {
+		// Module functions are checked by exprType, as they are typed
+	} else if s.code == structureCode["ST_CALL"] && s.children[0].code == structureCode["ATTRIBUTE"] {
+		err := a.checkMethodCall(s, vars, funcs)
+		if err != nil {
+			return err
+		}
+	} else if s.code == structureCode["ST_CALL"] && isBuiltinCall(s, funcs) {
+		// Concurrency functions are checked by exprType, as they are typed
 		name := s.children[0].text
-		var valid bool
-		for i := 0; i < len(funcs); i++ {
-			valid = false
-			if name == funcs[i].name {
-				valid = true
-				fn = funcs[i]
-				break
-			}
+		if (name == "go" || name == "defer") && a.raising[s.children[2].children[0].text] {
+			return createError([]string{"analyze.go", "analyze:ST_CALL"}, "\""+s.children[2].children[0].text+"\" may raise, so it can't be called with "+name, s.line)
 		}
+		// The body of a with is a closure in Go, which would run it early
+		if name == "defer" && a.withs > 0 {
+			return createError([]string{"analyze.go", "analyze:ST_CALL"}, "defer can't be used inside a with statement", s.line)
+		}
+	} else if s.code == structureCode["ST_CALL"] && isBuiltinFunction(s, funcs) {
+		// Built in functions are checked by exprType, as they are typed. The
+		// class given to isinstance isn't a value, so it is left out
+		if s.children[0].text == "isinstance" {
+			return a.analyze(s.children[2], vars, funcs)
+		}
+	} else if s.code == structureCode["ST_CALL"] {
+		fn, valid := findFunction(s.children[0].text, funcs)
 		if !valid {
 			return createError([]string{"analyze.go", "analyze:ST_CALL"}, "An attempt to call the non-existent function \""+s.children[0].text+"\" was made", s.line)
 		}
 
-		i := 2
+		// Calls have already been resolved into Go's order by resolveCall
 		pIndex := 0
+		for i := 2; i+1 < len(s.children); i += 2 {
+			arg := s.children[i]
 
-		for i+1 < len(s.children) {
-
-			if fn.params[pIndex] == "any" {
-				i += 2
-				pIndex++
+			if arg.code == structureCode["KEYWORD_ARG"] {
+				err := a.printArgument(arg, vars, funcs)
+				if err != nil {
+					return err
+				}
 				continue
 			}
 
-			if s.children[i].code != structureCode["IDENTIFIER"] {
-				switch s.children[i].code {
-				case structureCode["L_STRING"]:
-					if fn.params[pIndex] != "string" {
-						return createError([]string{"analyze.go", "analyze:ST_CALL"}, "Excpected "+fn.params[pIndex]+" got string in function call", s.line)
-					}
-				case structureCode["L_BOOL"]:
-					if fn.params[pIndex] != "bool" {
-						return createError([]string{"analyze.go", "analyze:ST_CALL"}, "Excpected "+fn.params[pIndex]+" got bool in function call", s.line)
-					}
-				case structureCode["L_INT"]:
-					valid := false
-					types := []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "float32", "float64"}
-					for j := 0; j < len(types); j++ {
-						if types[j] == fn.params[pIndex] {
-							valid = true
-							break
-						}
-					}
-					if !valid {
-						return createError([]string{"analyze.go", "analyze:ST_CALL"}, "Excpected "+fn.params[pIndex]+" got int in function call", s.line)
-					}
-				case structureCode["ST_CALL"]:
-					var insideCall Function
-
-					name := s.children[i].children[0].text
-					var valid bool
-					for i := 0; i < len(funcs); i++ {
-						valid = false
-						if name == funcs[i].name {
-							valid = true
-							insideCall = funcs[i]
-							break
-						}
-					}
-					if !valid {
-						return createError([]string{"analyze.go", "analyze:ST_CALL"}, "An attempt to call the non-existent function \""+name+"\" was made", s.line)
-					}
-
-					if fn.params[pIndex] != insideCall.varType {
-						return createError([]string{"analyze.go", "analyze:ST_CALL"}, "\""+name+"\" is the wrong type, expected "+fn.params[pIndex]+" got "+insideCall.varType, s.line)
-					}
-
-				default:
-					return createError([]string{"analyze.go", "analyze:ST_CALL"}, "How did you even...? "+fn.name, s.line)
-				}
-				i += 2
-				pIndex++
-				continue
+			want := fn.params[len(fn.params)-1]
+			if pIndex < len(fn.params)-1 || !fn.variadic {
+				want = fn.params[pIndex]
 			}
 
-			name := s.children[i].text
-			var variable Variable
-			var valid bool
-			for i := 0; i < len(vars); i++ {
-				valid = false
-				if name == vars[i].name {
-					valid = true
-					variable = vars[i]
-					break
-				}
+			settle(s, i, want)
+			t, err := a.typeChild(s, i, vars, funcs)
+			if err != nil {
+				return err
 			}
-			if !valid {
-				return createError([]string{"analyze.go", "analyze:ST_CALL"}, "An uninitialized variable was used in a function call", s.line)
+			if isTuple(t) {
+				return createError([]string{"analyze.go", "analyze:ST_CALL"}, "Multiple values can't be used as an argument", s.line)
 			}
 
-			if variable.varType != fn.params[pIndex] && fn.params[pIndex] != "any" {
-				return createError([]string{"analyze.go", "analyze:ST_CALL"}, "Wrong type used in function call", s.line)
+			if !assignable(want, t) {
+				return createError([]string{"analyze.go", "analyze:ST_CALL"}, "Excpected "+want+" got "+t+" in function call", s.line)
 			}
-
-			i += 2
 			pIndex++
 		}
+	} else if s.code == structureCode["ST_CLASS"] {
+		// Methods are analyzed here, so they aren't taken as functions
+		return a.analyzeClass(s, vars, funcs)
+	} else if s.code == structureCode["LAMBDA"] {
+		// Lambdas are checked by exprType, which knows their parameters
+		return nil
 	} else if s.code == structureCode["ST_FUNCTION"] {
-		i := 3
-		for s.children[i].code == structureCode["IDENTIFIER"] {
-			n := s.children[i]   // IDENTIFIER - name
-			t := s.children[i+2] // IDENTIFIER - type
-			v := Variable{n.text, t.text}
-			vars = append(vars, v)
+		f, err := functionSignature(s)
+		if err != nil {
+			return err
+		}
+		// A nested function is its own function in Go, so breaks and excepts
+		// around it don't reach into it
+		a.returns = append(a.returns, f.varType)
+		a.locals = append(a.locals, len(vars))
+		loops, withs, handling := a.loops, a.withs, a.handling
+		a.loops, a.withs, a.handling = 0, 0, 0
+		defer func() {
+			a.returns = a.returns[:len(a.returns)-1]
+			a.locals = a.locals[:len(a.locals)-1]
+			a.loops, a.withs, a.handling = loops, withs, handling
+		}()
+
+		for i := 3; s.children[i].code == structureCode["PARAMETER"]; i += 2 {
+			param := s.children[i]
+
+			if param.children[0].code == structureCode["MO_MUL"] {
+				n := param.children[1] // IDENTIFIER - name
+				t := param.children[3] // IDENTIFIER - type
+				vars = append(vars, Variable{n.text, "list[" + t.text + "]"})
+				continue
+			}
+
+			n := param.children[0] // IDENTIFIER - name
+			t := param.children[2] // IDENTIFIER - type
+			vars = append(vars, Variable{n.text, t.text})
+			if isCallable(t.text) {
+				funcs = append(funcs, callableFunction(n.text, t.text))
+			}
 
-			i += 4 // Next variable, otherwise this will end up on an anti-colon
+			if len(param.children) == 5 {
+				if !isConstant(param.children[4]) {
+					return createError([]string{"analyze.go", "analyze:ST_FUNCTION"}, "The default value of \""+n.text+"\" must be a literal", s.line)
+				}
+				dt, err := a.exprType(param.children[4], []Variable{}, []Function{})
+				if err != nil {
+					return err
+				}
+				if !assignable(t.text, dt) {
+					return createError([]string{"analyze.go", "analyze:ST_FUNCTION"}, "Excpected "+t.text+" got "+dt+" as the default of \""+n.text+"\"", s.line)
+				}
+			}
 		}
 	}
 
@@ -198,94 +266,167 @@ func (a *Analyzer) analyze(s Structure, vars []Variable, funcs []Function) error
 			return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "An uninitialized variable was used in a declaration", s.line)
 		}
 
-		i := 0
-		for i < len(s.children[4].children) {
-			if s.children[4].children[i].code != structureCode["IDENTIFIER"] {
-				switch s.children[4].children[i].code {
-				case structureCode["L_STRING"]:
-					if variable.varType != "string" {
-						return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "Excpected "+variable.varType+" got string in declaration", s.line)
-					}
-				case structureCode["L_BOOL"]:
-					if variable.varType != "bool" {
-						return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "Excpected "+variable.varType+" got bool in declaration", s.line)
-					}
-				case structureCode["L_INT"]:
-					valid := false
-					types := []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "float32", "float64"}
-					for j := 0; j < len(types); j++ {
-						if types[j] == variable.varType {
-							valid = true
-							break
-						}
-					}
-					if !valid {
-						return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "Excpected "+variable.varType+" got int in declaration", s.line)
-					}
-				case structureCode["ST_CALL"]:
-					var insideCall Function
-
-					name := s.children[4].children[i].children[0].text
-					var valid bool
-					for i := 0; i < len(funcs); i++ {
-						valid = false
-						if name == funcs[i].name {
-							valid = true
-							insideCall = funcs[i]
-							break
-						}
-					}
-					if !valid {
-						return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "An attempt to call the non-existent function \""+name+"\" was made", s.line)
-					}
-
-					if variable.varType != insideCall.varType {
-						return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "\""+name+"\" is the wrong type, expected "+variable.varType+" got "+insideCall.varType, s.line)
-					}
-
-				default:
-					return createError([]string{"analyze.go", "analyze:ST_CALL"}, "How did you even...? "+variable.name, s.line)
-				}
-				i += 2
-				continue
+		if isTuple(variable.varType) {
+			return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "Tuples can only be returned and unpacked, not stored", s.line)
+		}
+		if a.settings.bigInts && bigKeys(variable.varType) {
+			return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "A dict can't have int keys with -big-ints, as *big.Int keys are compared by pointer. Use int64", s.line)
+		}
+
+		settle(s, 4, variable.varType)
+		t, err := a.typeChild(s, 4, vars, funcs)
+		if err != nil {
+			return err
+		}
+		if !assignable(variable.varType, t) {
+			return createError([]string{"analyze.go", "analyze:ST_DECLARATION"}, "Excpected "+variable.varType+" got "+t+" in declaration", s.line)
+		}
+
+	} else if s.code == structureCode["ST_RAISE"] {
+		if len(s.children) == 1 {
+			if a.handling == 0 {
+				return createError([]string{"analyze.go", "analyze:ST_RAISE"}, "No active exception to reraise", s.line)
+			}
+		} else {
+			t, err := a.typeChild(s, 1, vars, funcs)
+			if err != nil {
+				return err
 			}
+			_, exception := exceptionParents[t]
+			if !exception {
+				return createError([]string{"analyze.go", "analyze:ST_RAISE"}, "Exceptions must derive from Exception, got "+t, s.line)
+			}
+		}
+	} else if s.code == structureCode["ST_EXCEPT"] {
+		a.handling++
+		defer func() { a.handling-- }()
 
-			i += 2
+		if len(s.children) > 3 {
+			class := s.children[1].text
+			_, exception := exceptionParents[class]
+			if !exception {
+				return createError([]string{"analyze.go", "analyze:ST_EXCEPT"}, "Can't catch \""+class+"\" as it isn't an exception", s.line)
+			}
+			if len(s.children) == 6 {
+				vars = append(vars, Variable{s.children[3].text, class})
+			}
+		}
+	} else if s.code == structureCode["ST_WHILE"] || s.code == structureCode["ST_ELIF"] {
+		// These conditions are evaluated more than once, or only sometimes,
+		// so a select can't be placed before them
+		err := a.analyze(s.children[1], vars, funcs)
+		if err != nil {
+			return err
+		}
+		if usesSelect(s.children[1]) {
+			return createError([]string{"analyze.go", "analyze:condition"}, "select can't be used in this condition, store the result in a variable first", s.line)
+		}
+		if s.code == structureCode["ST_WHILE"] {
+			return a.analyzeLoop(s, 4, vars, vars, funcs)
+		}
+		for i := 2; i < len(s.children); i++ {
+			err := a.analyze(s.children[i], vars, funcs)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if s.code == structureCode["ST_BREAK"] || s.code == structureCode["ST_CONTINUE"] {
+		if a.loops == 0 {
+			return createError([]string{"analyze.go", "analyze:" + s.text}, "'"+s.children[0].text+"' outside loop", s.line)
 		}
+	} else if s.code == structureCode["ST_WITH"] {
+		a.withs++
+		defer func() {
+			a.withs--
+		}()
+
+		for i := 1; s.children[i].code == structureCode["WITH_ITEM"]; i += 2 {
+			item := s.children[i]
+			t, err := a.typeChild(item, 0, vars, funcs)
+			if err != nil {
+				return err
+			}
 
+			entered := t
+			if t != "file" {
+				enter, hasEnter := a.method(t, "__enter__")
+				_, hasExit := a.method(t, "__exit__")
+				if !hasEnter || !hasExit {
+					return createError([]string{"analyze.go", "analyze:ST_WITH"}, t+" can't be used in a with statement, as it doesn't have __enter__ and __exit__", s.line)
+				}
+				entered = enter.varType
+			}
+
+			if len(item.children) == 3 {
+				if entered == "None" {
+					return createError([]string{"analyze.go", "analyze:ST_WITH"}, "The __enter__ of "+t+" returns None, so it can't be used with as", s.line)
+				}
+				vars = append(vars, Variable{item.children[2].text, entered})
+			}
+		}
+	} else if s.code == structureCode["ST_RETURN"] {
+		err := a.checkReturn(s, vars, funcs)
+		if err != nil {
+			return err
+		}
 	} else if s.code == structureCode["ST_FOR"] {
-		n := s.children[1] // IDENTIFIER - name
-		t := "int"         // IDENTIFIER - type
-		v := Variable{n.text, t}
-		vars = append(vars, v)
+		return a.analyzeFor(s, vars, funcs)
 	}
 
 	for i := 0; i < len(s.children); i++ {
+		if s.code == structureCode["PROGRAM"] {
+			a.top = i
+		}
 		if s.children[i].code == structureCode["ST_DECLARATION"] {
 			n := s.children[i].children[0] // IDENTIFIER - name
 			t := s.children[i].children[2] // IDENTIFIER - type
 			v := Variable{n.text, t.text}
 			vars = append(vars, v)
+			if isCallable(t.text) {
+				funcs = append(funcs, callableFunction(n.text, t.text))
+			}
 		}
 
-		if s.children[i].code == structureCode["ST_FUNCTION"] {
-			n := s.children[i].children[1] // IDENTIFIER - name
-
-			params := []string{}
+		if s.children[i].code == structureCode["ST_UNPACK"] {
+			declared, err := a.unpack(s.children[i], vars, funcs)
+			if err != nil {
+				return err
+			}
+			vars = append(vars, declared...)
+		}
 
-			j := 3
-			for s.children[i].children[j].code == structureCode["IDENTIFIER"] {
-				t := s.children[i].children[j+2] // IDENTIFIER - type
-				params = append(params, t.text)  // Add the type to the parameters of the function
+		if s.children[i].code == structureCode["ST_NONLOCAL"] || s.children[i].code == structureCode["ST_GLOBAL"] {
+			declared, err := a.outerVariables(s.children[i], vars)
+			if err != nil {
+				return err
+			}
+			vars = append(vars, declared...)
+		}
 
-				j += 4 // Next variable, otherwise this will end up on an arrow
+		if s.children[i].code == structureCode["ST_FUNCTION"] || s.children[i].code == structureCode["ST_CLASS"] {
+			f, err := a.declare(s.children[i])
+			if err != nil {
+				return err
 			}
+			funcs = append(funcs, f)
+		}
 
-			t := s.children[i].children[len(s.children[i].children)-3]
+		// Calls are rewritten in place, so the emitter gets every argument
+		// in the order Go takes them
+		if s.children[i].code == structureCode["ST_CALL"] {
+			resolved, err := a.resolveCall(s.children[i], vars, funcs)
+			if err != nil {
+				return err
+			}
+			s.children[i] = resolved
 
-			f := Function{n.text, params, t.text}
-			funcs = append(funcs, f)
+			_, err = a.typeChild(s, i, vars, funcs)
+			if err != nil {
+				return err
+			}
 		}
+
 		err := a.analyze(s.children[i], vars, funcs)
 		if err != nil {
 			return err
@@ -294,3 +435,1705 @@ func (a *Analyzer) analyze(s Structure, vars []Variable, funcs []Function) error
 
 	return nil
 }
+
+// The function a definition adds. Calling a class constructs it, taking the
+// arguments of __init__
+func (a *Analyzer) declare(s Structure) (Function, error) {
+	if s.code == structureCode["ST_FUNCTION"] {
+		return functionSignature(s)
+	}
+
+	c, err := classSignature(s)
+	if err != nil {
+		return Function{}, err
+	}
+	if a.classes == nil {
+		a.classes = map[string]Class{}
+	}
+	a.classes[c.name] = c
+
+	constructor := Function{name: c.name, params: []string{}, varType: c.name}
+	init, exists := a.method(c.name, "__init__")
+	if exists {
+		constructor = init
+		constructor.name = c.name
+		constructor.varType = c.name
+	}
+	return constructor, nil
+}
+
+// Works out which functions may raise an exception, either with raise, or by
+// calling a function that may without catching everything. Go needs these
+// to return an error. Functions that aren't in the program are known, and say
+// whether they raise
+func (a *Analyzer) raisingFunctions(program Structure, known map[string]bool) map[string]bool {
+	// open raises when the file can't be opened, unless the program has its
+	// own open
+	raising := map[string]bool{"open": true}
+	for name, raises := range known {
+		if raises {
+			raising[name] = true
+		} else {
+			delete(raising, name)
+		}
+	}
+	for i := 0; i < len(program.children); i++ {
+		if program.children[i].code == structureCode["ST_FUNCTION"] && program.children[i].children[1].text == "open" {
+			delete(raising, "open")
+		}
+	}
+	a.goRaising(program, raising)
+
+	functions := nestedFunctions(program, []Structure{})
+	changed := true
+	for changed {
+		changed = false
+		for i := 0; i < len(functions); i++ {
+			// The top level code of modules is made into functions with no
+			// line, which report exceptions themselves
+			f := functions[i]
+			if f.line == -1 || raising[f.children[1].text] {
+				continue
+			}
+			if mayRaise(f.children[len(f.children)-1], raising) {
+				raising[f.children[1].text] = true
+				changed = true
+			}
+		}
+	}
+
+	return raising
+}
+
+// The functions defined in a structure, including those defined in other
+// functions. Methods aren't, but the functions in them are
+func nestedFunctions(s Structure, found []Structure) []Structure {
+	for i := 0; i < len(s.children); i++ {
+		child := s.children[i]
+		if child.code == structureCode["ST_CLASS"] {
+			body := child.children[len(child.children)-1]
+			for j := 0; j < len(body.children); j++ {
+				found = nestedFunctions(body.children[j], found)
+			}
+			continue
+		}
+		if child.code == structureCode["ST_FUNCTION"] {
+			found = append(found, child)
+		}
+		found = nestedFunctions(child, found)
+	}
+	return found
+}
+
+// Whether an exception could escape a structure. Defining a function or a
+// lambda doesn't run it
+func mayRaise(s Structure, raising map[string]bool) bool {
+	return raises(s, raising, true)
+}
+
+// Whether an exception other than one from a built in function or from
+// dividing by zero could escape a structure. Methods and lambdas have no
+// error to return, so these end the program with a traceback in them instead
+func mayRaiseBesidesBuiltins(s Structure, raising map[string]bool) bool {
+	return raises(s, raising, false)
+}
+
+func raises(s Structure, raising map[string]bool, builtins bool) bool {
+	if s.code == structureCode["ST_RAISE"] {
+		return true
+	}
+	if s.code == structureCode["ST_FUNCTION"] || s.code == structureCode["LAMBDA"] {
+		return false
+	}
+	if s.code == structureCode["ST_CALL"] && raisingCall(s, raising) && (builtins || !builtinRaises(s)) {
+		return true
+	}
+	if builtins && (dividesByZero(s) || variableStep(s)) {
+		return true
+	}
+
+	if s.code == structureCode["ST_TRY"] {
+		caught := false
+		for i := 3; i < len(s.children); i++ {
+			except := s.children[i]
+			if len(except.children) == 3 || except.children[1].text == "Exception" {
+				caught = true
+			}
+			if raises(except.children[len(except.children)-1], raising, builtins) {
+				return true
+			}
+		}
+		return !caught && raises(s.children[2], raising, builtins)
+	}
+
+	for i := 0; i < len(s.children); i++ {
+		if raises(s.children[i], raising, builtins) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a call may raise, which calls through a module are keyed by with
+// their full name, such as strconv.Atoi
+func raisingCall(s Structure, raising map[string]bool) bool {
+	return raising[callName(s)] || builtinRaises(s)
+}
+
+// Whether a call to a built in function may raise, once it has been typed.
+// int() and float() raise a ValueError for a string they can't parse, min()
+// and max() for an empty sequence, ord() a TypeError for a string that isn't
+// one character, and input() an EOFError at the end of its input. str.split
+// raises a ValueError for an empty separator
+func builtinRaises(s Structure) bool {
+	callee := s.children[0]
+	if callee.code == structureCode["ATTRIBUTE"] && callee.varType == "string" && callee.children[len(callee.children)-1].text == "split" && len(s.children) == 4 {
+		separator, constant := constantString(s.children[2])
+		return !constant || separator == ""
+	}
+	if callee.varType != "builtin" {
+		return false
+	}
+	switch callee.text {
+	case "int", "float":
+		return len(s.children) > 3 && s.children[2].varType == "string"
+	case "min", "max":
+		return len(s.children) == 4
+	case "ord":
+		_, constant := constantCharacter(s.children[2])
+		return !constant
+	case "input":
+		return true
+	}
+	return false
+}
+
+// Whether an expression, an augmented assignment or a call to divmod may
+// divide by zero, which raises a ZeroDivisionError. The analyzer marks the
+// operators that may, once the types of their operands are known
+func dividesByZero(s Structure) bool {
+	switch s.code {
+	case structureCode["EXPRESSION"]:
+		for i := 1; i < len(s.children); i += 2 {
+			if s.children[i].varType == "raises" {
+				return true
+			}
+		}
+	case structureCode["ST_MANIPULATION"]:
+		return s.children[1].varType == "raises"
+	case structureCode["ST_CALL"]:
+		if s.children[0].text != "divmod" || s.children[0].varType != "builtin" {
+			return false
+		}
+		divisor := s.children[4]
+		return divisor.code != structureCode["EXPRESSION"] || !nonZeroConstant(divisor, arrange(divisor))
+	}
+	return false
+}
+
+// Whether a call to range has a step that isn't a constant, which raises a
+// ValueError when it is zero
+func variableStep(s Structure) bool {
+	if s.code != structureCode["ST_CALL"] || s.children[0].text != "range" || len(s.children) != 8 {
+		return false
+	}
+	step := s.children[6]
+	return step.code != structureCode["EXPRESSION"] || !nonZeroConstant(step, arrange(step))
+}
+
+// The text of a string constant, and whether it is one
+func constantString(s Structure) (string, bool) {
+	if s.code != structureCode["EXPRESSION"] || len(s.children) != 1 || s.children[0].code != structureCode["L_STRING"] {
+		return "", false
+	}
+	text, err := strconv.Unquote(s.children[0].text)
+	return text, err == nil
+}
+
+// The character a string constant holds, if it holds exactly one
+func constantCharacter(s Structure) (rune, bool) {
+	text, constant := constantString(s)
+	if !constant || utf8.RuneCountInString(text) != 1 {
+		return 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(text)
+	return r, true
+}
+
+// The name a call is made with, including the module or receiver before it
+func callName(s Structure) string {
+	callee := s.children[0]
+	if callee.code != structureCode["ATTRIBUTE"] {
+		return callee.text
+	}
+	name := ""
+	for i := 0; i < len(callee.children); i++ {
+		name += callee.children[i].text
+	}
+	return name
+}
+
+// Python's open, which the emitter provides as pyOpen
+func openFunction() Function {
+	mode := createStructure("EXPRESSION", "EXPRESSION", -1)
+	mode.children = append(mode.children, createStructure("L_STRING", "\"r\"", -1))
+
+	return Function{
+		name:       "open",
+		params:     []string{"string", "string"},
+		varType:    "file",
+		names:      []string{"file", "mode"},
+		defaults:   []Structure{{}, mode},
+		positional: 2,
+		builtin:    true,
+	}
+}
+
+// Constructors for the built in exceptions, which take an optional message
+func exceptionFunctions() []Function {
+	message := createStructure("EXPRESSION", "EXPRESSION", -1)
+	message.children = append(message.children, createStructure("L_STRING", "\"\"", -1))
+
+	funcs := []Function{}
+	for name := range exceptionParents {
+		funcs = append(funcs, Function{
+			name:       name,
+			params:     []string{"string"},
+			varType:    name,
+			names:      []string{"message"},
+			defaults:   []Structure{message},
+			positional: 1,
+		})
+	}
+	return funcs
+}
+
+// Reads the signature of a function from its definition
+func functionSignature(s Structure) (Function, error) {
+	f := Function{name: s.children[1].text}
+
+	var variadic Structure
+	for i := 3; s.children[i].code == structureCode["PARAMETER"]; i += 2 {
+		param := s.children[i]
+
+		if param.children[0].code == structureCode["MO_MUL"] {
+			if f.variadic {
+				return f, createError([]string{"analyze.go", "functionSignature"}, "\""+f.name+"\" can only have one variadic parameter", s.line)
+			}
+			f.variadic = true
+			variadic = param
+			continue
+		}
+
+		hasDefault := len(param.children) == 5
+		if !f.variadic {
+			if !hasDefault && f.positional > 0 && len(f.defaults[f.positional-1].children) > 0 {
+				return f, createError([]string{"analyze.go", "functionSignature"}, "Non-default parameter \""+param.children[0].text+"\" follows a default parameter", s.line)
+			}
+			f.positional++
+		}
+
+		f.names = append(f.names, param.children[0].text)
+		f.params = append(f.params, param.children[2].text)
+		if hasDefault {
+			f.defaults = append(f.defaults, param.children[4])
+		} else {
+			f.defaults = append(f.defaults, Structure{})
+		}
+	}
+
+	// Go only allows the variadic parameter at the end
+	if f.variadic {
+		f.names = append(f.names, variadic.children[1].text)
+		f.params = append(f.params, variadic.children[3].text)
+		f.defaults = append(f.defaults, Structure{})
+	}
+
+	// Only main lacks a return type, which is ARROW, the type, COLON, then BLOCK
+	f.varType = "None"
+	if s.children[len(s.children)-4].code == structureCode["ARROW"] {
+		f.varType = s.children[len(s.children)-3].text
+	}
+	return f, nil
+}
+
+// Rewrites a call so that it has every argument in the order Go takes them,
+// matching keyword arguments to their parameters and filling in defaults
+func (a *Analyzer) resolveCall(s Structure, vars []Variable, funcs []Function) (Structure, error) {
+	if s.children[0].code == structureCode["ATTRIBUTE"] {
+		// Methods of classes, which are the only ones with keyword arguments
+		callee := s.children[0]
+		receiver, exists := findVariable(callee.children[0].text, vars)
+		if !exists || len(callee.children) != 3 || a.classes[receiver.varType].name == "" {
+			return s, nil
+		}
+		method, exists := a.method(receiver.varType, callee.children[2].text)
+		if !exists {
+			return s, nil // Reported by methodType
+		}
+		return a.resolveArguments(s, method)
+	}
+
+	fn, valid := findFunction(s.children[0].text, funcs)
+	if !valid && (s.children[0].text == "go" || s.children[0].text == "defer") {
+		return a.resolveGo(s, vars, funcs)
+	}
+	if !valid || fn.name == "print" {
+		return s, nil // Missing functions are reported by analyze, and print is emitted by hand
+	}
+	return a.resolveArguments(s, fn)
+}
+
+// Puts the arguments of a call to fn in the order Go takes them
+func (a *Analyzer) resolveArguments(s Structure, fn Function) (Structure, error) {
+
+	named := len(fn.params)
+	if fn.variadic {
+		named--
+	}
+
+	args := make([]Structure, named)
+	given := make([]bool, named)
+	extra := []Structure{}
+	positional := 0
+	keywords := false
+
+	for i := 2; i+1 < len(s.children); i += 2 {
+		arg := s.children[i]
+
+		if arg.code != structureCode["KEYWORD_ARG"] {
+			if keywords {
+				return s, createError([]string{"analyze.go", "resolveCall"}, "Positional argument follows keyword argument in call to \""+fn.name+"\"", s.line)
+			}
+			if positional < fn.positional {
+				args[positional] = arg
+				given[positional] = true
+				positional++
+			} else if fn.variadic {
+				extra = append(extra, arg)
+			} else {
+				return s, createError([]string{"analyze.go", "resolveCall"}, "Too many arguments in call to \""+fn.name+"\"", s.line)
+			}
+			continue
+		}
+
+		keywords = true
+		name := arg.children[0].text
+		index := -1
+		for j := 0; j < named; j++ {
+			if fn.names[j] == name {
+				index = j
+				break
+			}
+		}
+		if index == -1 {
+			return s, createError([]string{"analyze.go", "resolveCall"}, "\""+fn.name+"\" has no parameter called \""+name+"\"", s.line)
+		}
+		if given[index] {
+			return s, createError([]string{"analyze.go", "resolveCall"}, "\""+name+"\" was given more than once in call to \""+fn.name+"\"", s.line)
+		}
+		args[index] = arg.children[2]
+		given[index] = true
+	}
+
+	for i := 0; i < named; i++ {
+		if given[i] {
+			continue
+		}
+		if len(fn.defaults[i].children) == 0 {
+			return s, createError([]string{"analyze.go", "resolveCall"}, "Missing argument \""+fn.names[i]+"\" in call to \""+fn.name+"\"", s.line)
+		}
+		args[i] = fn.defaults[i]
+	}
+	args = append(args, extra...)
+
+	resolved := s
+	resolved.children = []Structure{s.children[0], s.children[1]}
+	for i := 0; i < len(args); i++ {
+		if i > 0 {
+			resolved.children = append(resolved.children, createStructure("SEP", ",", s.line))
+		}
+		resolved.children = append(resolved.children, args[i])
+	}
+	resolved.children = append(resolved.children, s.children[len(s.children)-1])
+
+	return resolved, nil
+}
+
+// Checks that what is returned matches the function's return type
+func (a *Analyzer) checkReturn(s Structure, vars []Variable, funcs []Function) error {
+	want := "None"
+	if len(a.returns) > 0 {
+		want = a.returns[len(a.returns)-1]
+	}
+
+	if len(s.children) == 2 {
+		settle(s, 1, want)
+	}
+
+	got := []string{}
+	for i := 1; i < len(s.children); i += 2 {
+		t, err := a.typeChild(s, i, vars, funcs)
+		if err != nil {
+			return err
+		}
+		got = append(got, t)
+	}
+
+	if len(got) == 0 {
+		if want != "None" {
+			return createError([]string{"analyze.go", "checkReturn"}, "Expected a "+want+" to be returned", s.line)
+		}
+		return nil
+	}
+
+	// Returning a call to a function with the same tuple type
+	if len(got) == 1 && (!isTuple(got[0]) || got[0] == want) {
+		if !assignable(want, got[0]) {
+			return createError([]string{"analyze.go", "checkReturn"}, "Excpected "+want+" got "+got[0]+" in return", s.line)
+		}
+		return nil
+	}
+
+	if !isTuple(want) {
+		return createError([]string{"analyze.go", "checkReturn"}, "Excpected "+want+" got "+strconv.Itoa(len(got))+" values in return", s.line)
+	}
+	_, elements := typeArguments(want)
+	if len(elements) != len(got) {
+		return createError([]string{"analyze.go", "checkReturn"}, "Excpected "+strconv.Itoa(len(elements))+" values got "+strconv.Itoa(len(got))+" in return", s.line)
+	}
+	for i := 0; i < len(got); i++ {
+		if !assignable(elements[i], got[i]) {
+			return createError([]string{"analyze.go", "checkReturn"}, "Excpected "+elements[i]+" got "+got[i]+" for value "+strconv.Itoa(i+1)+" in return", s.line)
+		}
+	}
+	return nil
+}
+
+// Checks an unpacking assignment, such as q, r = divmod2(7, 2), returning
+// the variables it declares. New targets are given their type, and if every
+// target is new the assignment is marked to be emitted with :=
+func (a *Analyzer) unpack(s Structure, vars []Variable, funcs []Function) ([]Variable, error) {
+	assign := 0
+	for s.children[assign].code != structureCode["ASSIGN"] {
+		assign++
+	}
+
+	values := []string{}
+	for i := assign + 1; i < len(s.children); i += 2 {
+		t, err := a.typeChild(s, i, vars, funcs)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, t)
+	}
+	if len(values) == 1 && isTuple(values[0]) {
+		_, values = typeArguments(values[0])
+	}
+
+	targets := (assign + 1) / 2
+	if len(values) != targets {
+		return nil, createError([]string{"analyze.go", "unpack"}, "Cannot unpack "+strconv.Itoa(len(values))+" values into "+strconv.Itoa(targets)+" variables", s.line)
+	}
+
+	declared := []Variable{}
+	for i := 0; i < assign; i += 2 {
+		name := s.children[i].text
+		t := values[i/2]
+		if isTuple(t) {
+			return nil, createError([]string{"analyze.go", "unpack"}, "Multiple values can't be unpacked into \""+name+"\"", s.line)
+		}
+		if t == "Chan" {
+			return nil, createError([]string{"analyze.go", "unpack"}, "The element type of the channel for \""+name+"\" is unknown, declare it with a type instead", s.line)
+		}
+
+		variable, exists := findVariable(name, vars)
+		if exists {
+			err := a.checkAssignable(name, vars, s.line)
+			if err != nil {
+				return nil, err
+			}
+			if !assignable(variable.varType, t) {
+				return nil, createError([]string{"analyze.go", "unpack"}, "Excpected "+variable.varType+" got "+t+" for \""+name+"\"", s.line)
+			}
+			continue
+		}
+		for j := 0; j < len(declared); j++ {
+			if declared[j].name == name {
+				return nil, createError([]string{"analyze.go", "unpack"}, "\""+name+"\" is unpacked into more than once", s.line)
+			}
+		}
+
+		s.children[i].varType = defaultType(t)
+		declared = append(declared, Variable{name, defaultType(t)})
+	}
+
+	if len(declared) == targets {
+		s.children[assign].text = ":="
+	}
+	return declared, nil
+}
+
+// Checks an augmented assignment, such as x += 1. Each operator only works
+// on some types, and the result has to be stored back in the same type
+func (a *Analyzer) checkAugmented(s Structure, vars []Variable, funcs []Function) error {
+	want, err := a.typeChild(s, 0, vars, funcs)
+	if err != nil && s.children[0].code == structureCode["IDENTIFIER"] {
+		return createError([]string{"analyze.go", "checkAugmented"}, "An attempt to manipulate an uninitialized variable was made", s.line)
+	}
+	if err != nil {
+		return err
+	}
+	if s.children[0].code == structureCode["IDENTIFIER"] {
+		err = a.checkAssignable(s.children[0].text, vars, s.line)
+		if err != nil {
+			return err
+		}
+	}
+	op := s.children[1].text
+	if op != "<<=" && op != ">>=" {
+		settle(s, 2, want)
+	}
+	t, err := a.typeChild(s, 2, vars, funcs)
+	if err != nil {
+		return err
+	}
+
+	base, _ := typeArguments(want)
+	valid := false
+	switch op {
+	case "+=":
+		valid = isNumeric(want) || want == "string" || base == "list"
+	case "-=", "*=", "//=", "%=", "**=":
+		valid = isNumeric(want)
+	case "/=":
+		// Python's / always gives a float
+		valid = want == "float32" || want == "float64"
+	default:
+		valid = isInteger(want)
+	}
+	if !valid {
+		return createError([]string{"analyze.go", "checkAugmented"}, op+" can't be used on "+want, s.line)
+	}
+
+	if op == "<<=" || op == ">>=" {
+		if !isInteger(defaultType(t)) {
+			return createError([]string{"analyze.go", "checkAugmented"}, "Excpected an integer got "+t+" for the shift count", s.line)
+		}
+		return nil
+	}
+	if !assignable(want, t) {
+		return createError([]string{"analyze.go", "checkAugmented"}, "Excpected "+want+" got "+t+" in "+op, s.line)
+	}
+	divides := zeroDivision[op[:len(op)-1]][0] != ""
+	if divides && s.children[2].code == structureCode["EXPRESSION"] {
+		err = checkDivisor(s.children[2], op[:len(op)-1], arrange(s.children[2]), isFloat(want) || isFloat(t))
+		if err != nil {
+			return err
+		}
+	}
+	if divides && !a.settings.goSemantics && !(s.children[2].code == structureCode["EXPRESSION"] && nonZeroConstant(s.children[2], arrange(s.children[2]))) {
+		s.children[1].varType = "raises"
+	}
+	if op == "**=" && isInteger(want) && isConstant(s.children[2]) && strings.HasPrefix(s.children[2].children[0].text, "-") {
+		return createError([]string{"analyze.go", "checkAugmented"}, "A negative power of an int is a float, so it can't be stored in "+want, s.line)
+	}
+	return nil
+}
+
+// Whether an expression is made of only literals
+func isConstant(s Structure) bool {
+	for i := 0; i < len(s.children); i += 2 {
+		switch s.children[i].code {
+		case structureCode["L_BOOL"], structureCode["L_INT"], structureCode["L_STRING"], structureCode["L_NULL"]:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Checks the keyword arguments print takes
+func (a *Analyzer) printArgument(arg Structure, vars []Variable, funcs []Function) error {
+	t, err := a.typeChild(arg, 2, vars, funcs)
+	if err != nil {
+		return err
+	}
+
+	var want []string
+	switch arg.children[0].text {
+	case "sep", "end":
+		want = []string{"string", "None"}
+	case "file":
+		want = []string{"file"}
+	case "flush":
+		want = []string{"bool"}
+	default:
+		return createError([]string{"analyze.go", "printArgument"}, "\""+arg.children[0].text+"\" is an invalid keyword argument for print", arg.line)
+	}
+
+	for i := 0; i < len(want); i++ {
+		if assignable(want[i], t) {
+			return nil
+		}
+	}
+	return createError([]string{"analyze.go", "printArgument"}, "Excpected "+want[0]+" got "+t+" for \""+arg.children[0].text+"\"", arg.line)
+}
+
+// Works out the type of a child, storing it on the child for the emitter
+func (a *Analyzer) typeChild(s Structure, i int, vars []Variable, funcs []Function) (string, error) {
+	t, err := a.exprType(s.children[i], vars, funcs)
+	if err != nil {
+		return "", err
+	}
+	s.children[i].varType = t
+	return t, nil
+}
+
+// Works out the type of an expression, making sure everything used in it
+// exists
+func (a *Analyzer) exprType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	switch s.code {
+	case structureCode["L_STRING"]:
+		return "string", nil
+	case structureCode["L_BOOL"]:
+		return "bool", nil
+	case structureCode["L_NULL"]:
+		return "None", nil
+	case structureCode["L_INT"]:
+		if strings.Contains(s.text, ".") {
+			return "untyped float", nil
+		}
+		return "untyped int", nil
+	case structureCode["IDENTIFIER"]:
+		variable, valid := findVariable(s.text, vars)
+		if fn, defined := findFunction(s.text, funcs); !valid && defined {
+			return a.functionValueType(s, fn)
+		}
+		if !valid && isBuiltinName(s.text) {
+			return "", createError([]string{"analyze.go", "exprType:IDENTIFIER"}, "The built in function \""+s.text+"\" can't be used as a value, call it in a lambda instead", s.line)
+		}
+		if !valid {
+			return "", createError([]string{"analyze.go", "exprType:IDENTIFIER"}, "An uninitialized variable was used in an expression", s.line)
+		}
+		return variable.varType, nil
+	case structureCode["ST_CALL"]:
+		if name := a.moduleFunction(s.children[0], vars, funcs); name != "" {
+			s.children[0].varType = "module"
+			return a.moduleCallType(s, name, vars, funcs)
+		}
+		if s.children[0].code == structureCode["ATTRIBUTE"] {
+			return a.methodType(s, vars, funcs)
+		}
+		if isBuiltinCall(s, funcs) {
+			s.children[0].varType = "GoType"
+			return a.concurrencyType(s, vars, funcs)
+		}
+		if isBuiltinFunction(s, funcs) {
+			s.children[0].varType = "builtin"
+			return a.builtinType(s, vars, funcs)
+		}
+		fn, valid := findFunction(s.children[0].text, funcs)
+		if !valid {
+			return "", createError([]string{"analyze.go", "exprType:ST_CALL"}, "An attempt to call the non-existent function \""+s.children[0].text+"\" was made", s.line)
+		}
+		if fn.builtin {
+			s.children[0].varType = "builtin"
+		}
+		return fn.varType, nil
+	case structureCode["LAMBDA"]:
+		if !isCallable(s.varType) {
+			return "", createError([]string{"analyze.go", "exprType:LAMBDA"}, "A lambda has to be used as a Callable, such as f: Callable[[int], int] = lambda x: x + 1", s.line)
+		}
+		params, result := callableSignature(s.varType)
+		return a.lambdaType(s, params, result, vars, funcs)
+	case structureCode["LIST"]:
+		return a.literalType(s, "list", vars, funcs)
+	case structureCode["DICT"]:
+		return a.literalType(s, "dict", vars, funcs)
+	case structureCode["ATTRIBUTE"]:
+		name := ""
+		for i := 0; i < len(s.children); i++ {
+			name += s.children[i].text
+		}
+
+		// Fields of objects, with the class kept on the object for the emitter
+		object, exists := findVariable(s.children[0].text, vars)
+		if exists {
+			class, exists := a.classes[object.varType]
+			if !exists || len(s.children) != 3 {
+				return "", createError([]string{"analyze.go", "exprType:ATTRIBUTE"}, "\""+name+"\" doesn't exist", s.line)
+			}
+			for i := 0; i < len(class.fields); i++ {
+				if class.fields[i].name == s.children[2].text {
+					s.children[0].varType = class.name
+					return class.fields[i].varType, nil
+				}
+			}
+			return "", createError([]string{"analyze.go", "exprType:ATTRIBUTE"}, class.name+" has no field \""+s.children[2].text+"\"", s.line)
+		}
+		if module := moduleName(s, a.modules); isGoModule(module) {
+			return a.goValueType(s, module)
+		}
+		t, exists := attributeTypes[name]
+		if !exists {
+			return "", createError([]string{"analyze.go", "exprType:ATTRIBUTE"}, "\""+name+"\" doesn't exist", s.line)
+		}
+		return t, nil
+	case structureCode["EXPRESSION"]:
+		for i := 0; i < len(s.children); i += 2 {
+			operand, err := a.typeChild(s, i, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			if isTuple(operand) && len(s.children) > 1 {
+				return "", createError([]string{"analyze.go", "exprType:EXPRESSION"}, "Multiple values can't be used in an expression", s.line)
+			}
+		}
+		t := s.children[0].varType
+		if len(s.children) > 1 {
+			var err error
+			t, err = operationType(s, arrange(s), a.settings)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// Constants have to fit where they are stored
+		if t == "untyped int" && fixedWidth(s.varType, a.settings) {
+			err := checkConstant(s, arrange(s), s.varType)
+			if err != nil {
+				return "", err
+			}
+		}
+		if t == "untyped int" && s.varType == "any" && !a.settings.bigInts {
+			err := checkConstant(s, arrange(s), "int")
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// Any int stored as a big int is converted, as is a constant too
+		// big for Go
+		if a.settings.bigInts && isIntegral(t) && (s.varType == "int" || s.varType == "any" && overflows(s)) {
+			return "int", nil
+		}
+		// Float constants are worked out in the float type they are stored
+		// as
+		if t == "untyped float" && (s.varType == "float32" || s.varType == "float64") {
+			return s.varType, nil
+		}
+		return t, nil
+	}
+
+	return "", createError([]string{"analyze.go", "exprType"}, "How did you even...? "+s.text, s.line)
+}
+
+// The type of a list or dict literal, from its items, or from where it is
+// stored if settle gave it one
+func (a *Analyzer) literalType(s Structure, kind string, vars []Variable, funcs []Function) (string, error) {
+	step := 2
+	if kind == "dict" {
+		step = 4
+	}
+
+	_, settled := typeArguments(s.varType)
+	types := []string{"", ""}
+	for i := 1; i+1 < len(s.children); i += step {
+		for j := 0; j < step/2; j++ {
+			if len(settled) == step/2 {
+				settle(s, i+j*2, settled[j])
+			}
+			t, err := a.typeChild(s, i+j*2, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			if isTuple(t) {
+				return "", createError([]string{"analyze.go", "literalType"}, "Multiple values can't be stored in a "+kind, s.line)
+			}
+
+			if len(settled) == step/2 {
+				if !assignable(settled[j], t) {
+					return "", createError([]string{"analyze.go", "literalType"}, "Excpected "+settled[j]+" got "+t+" in "+kind, s.line)
+				}
+				continue
+			}
+			if types[j] == "" {
+				types[j] = t
+				continue
+			}
+			combined, valid := combineTypes(types[j], t)
+			if !valid {
+				return "", createError([]string{"analyze.go", "literalType"}, "Mismatched types "+types[j]+" and "+t+" in "+kind, s.line)
+			}
+			types[j] = combined
+		}
+	}
+
+	if len(settled) == step/2 {
+		return s.varType, nil
+	}
+	if types[0] == "" {
+		example := "x: list[int] = []"
+		if kind == "dict" {
+			example = "x: dict[string, int] = {}"
+		}
+		return "", createError([]string{"analyze.go", "literalType"}, "An empty "+kind+" needs a type, such as "+example, s.line)
+	}
+
+	t := "list[" + defaultType(types[0]) + "]"
+	if kind == "dict" {
+		t = "dict[" + defaultType(types[0]) + ", " + defaultType(types[1]) + "]"
+	}
+	if a.settings.bigInts && bigKeys(t) {
+		return "", createError([]string{"analyze.go", "literalType"}, "A dict can't have int keys with -big-ints, as *big.Int keys are compared by pointer. Use int64", s.line)
+	}
+
+	// Items stored as big ints are converted by the emitter
+	for i := 1; a.settings.bigInts && i+1 < len(s.children); i += step {
+		for j := 0; j < step/2; j++ {
+			if defaultType(types[j]) == "int" {
+				s.children[i+j*2].varType = "int"
+			}
+		}
+	}
+	return t, nil
+}
+
+// The types of attributes of modules
+var attributeTypes map[string]string = map[string]string{
+	"math.e":     "float64",
+	"math.inf":   "float64",
+	"math.pi":    "float64",
+	"sys.argv":   "list[string]",
+	"sys.stderr": "file",
+	"sys.stdout": "file",
+}
+
+var numericTypes []string = []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune", "float32", "float64"}
+
+// Splits a type such as tuple[int, string] into tuple, and int and string
+func typeArguments(t string) (string, []string) {
+	start := strings.Index(t, "[")
+	if start == -1 || !strings.HasSuffix(t, "]") {
+		return t, []string{}
+	}
+
+	args := []string{}
+	depth := 0
+	last := start + 1
+	for i := start + 1; i < len(t)-1; i++ {
+		switch t[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(t[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	args = append(args, strings.TrimSpace(t[last:len(t)-1]))
+
+	return t[:start], args
+}
+
+func isTuple(t string) bool {
+	base, _ := typeArguments(t)
+	return base == "tuple"
+}
+
+// The type untyped constants become when stored without a type
+func defaultType(t string) string {
+	if t == "untyped int" {
+		return "int"
+	}
+	if t == "untyped float" {
+		return "float64"
+	}
+	return t
+}
+
+func isInteger(t string) bool {
+	return isNumeric(t) && !strings.HasPrefix(t, "float")
+}
+
+func isNumeric(t string) bool {
+	for i := 0; i < len(numericTypes); i++ {
+		if numericTypes[i] == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a value of type got can be stored somewhere of type want
+func assignable(want, got string) bool {
+	if want == got || want == "any" {
+		return true
+	}
+	if _, exception := exceptionParents[want]; exception {
+		for parent := exceptionParents[got]; parent != ""; parent = exceptionParents[parent] {
+			if parent == want {
+				return true
+			}
+		}
+	}
+	if got == "untyped int" {
+		return isNumeric(want)
+	}
+	if got == "untyped float" {
+		return want == "float32" || want == "float64"
+	}
+	if got == "Chan" {
+		base, _ := typeArguments(want)
+		return base == "Chan"
+	}
+	return false
+}
+
+// The type of two operands used together, the same way Go treats untyped
+// constants
+func combineTypes(a, b string) (string, bool) {
+	if a == b {
+		return a, true
+	}
+	if a == "untyped int" && b == "untyped float" || a == "untyped float" && b == "untyped int" {
+		return "untyped float", true
+	}
+	if assignable(a, b) {
+		return a, true
+	}
+	if assignable(b, a) {
+		return b, true
+	}
+	return "", false
+}
+
+func findVariable(name string, vars []Variable) (Variable, bool) {
+	for i := len(vars) - 1; i >= 0; i-- {
+		if vars[i].name == name {
+			return vars[i], true
+		}
+	}
+	return Variable{}, false
+}
+
+func findFunction(name string, funcs []Function) (Function, bool) {
+	for i := len(funcs) - 1; i >= 0; i-- {
+		if funcs[i].name == name {
+			return funcs[i], true
+		}
+	}
+	return Function{}, false
+}
+
+// Calls to the concurrency functions GoType provides, unless the program
+// defines its own function with the same name
+var concurrencyCalls []string = []string{"go", "defer", "make_chan", "select", "recv", "send", "default", "WaitGroup"}
+
+func isBuiltinCall(s Structure, funcs []Function) bool {
+	if s.children[0].code != structureCode["FUNC_NAME"] {
+		return false
+	}
+	if _, defined := findFunction(s.children[0].text, funcs); defined {
+		return false
+	}
+	for i := 0; i < len(concurrencyCalls); i++ {
+		if concurrencyCalls[i] == s.children[0].text {
+			return true
+		}
+	}
+	return false
+}
+
+// Rewrites go(f, args...) and defer(f, args...) so that the only argument is
+// the call to f, which is resolved like any other call
+func (a *Analyzer) resolveGo(s Structure, vars []Variable, funcs []Function) (Structure, error) {
+	if len(s.children) > 2 && s.children[2].code == structureCode["ST_CALL"] {
+		return s, nil // Already resolved
+	}
+
+	if len(s.children) < 4 || s.children[2].code != structureCode["EXPRESSION"] || len(s.children[2].children) != 1 || s.children[2].children[0].code != structureCode["IDENTIFIER"] {
+		return s, createError([]string{"analyze.go", "resolveGo"}, s.children[0].text+" needs the name of a function to call", s.line)
+	}
+	name := s.children[2].children[0]
+	if _, exists := findFunction(name.text, funcs); !exists {
+		return s, createError([]string{"analyze.go", "resolveGo"}, "An attempt to call the non-existent function \""+name.text+"\" was made", s.line)
+	}
+
+	call := createStructure("ST_CALL", "ST_CALL", s.line)
+	call.children = append(call.children, createStructure("FUNC_NAME", name.text, name.line), createStructure("L_PAREN", "(", s.line))
+	if len(s.children) > 4 {
+		call.children = append(call.children, s.children[4:len(s.children)-1]...)
+	}
+	call.children = append(call.children, createStructure("R_PAREN", ")", s.line))
+
+	call, err := a.resolveCall(call, vars, funcs)
+	if err != nil {
+		return s, err
+	}
+
+	resolved := s
+	resolved.children = []Structure{s.children[0], s.children[1], call, s.children[len(s.children)-1]}
+	return resolved, nil
+}
+
+// The types of the concurrency functions, checking their arguments
+func (a *Analyzer) concurrencyType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	args := (len(s.children) - 2) / 2
+	for i := 2; i+1 < len(s.children); i += 2 {
+		if s.children[i].code == structureCode["KEYWORD_ARG"] {
+			return "", createError([]string{"analyze.go", "concurrencyType"}, s.children[0].text+" doesn't take keyword arguments", s.line)
+		}
+	}
+
+	switch s.children[0].text {
+	case "go", "defer":
+		return "None", nil
+	case "make_chan":
+		if args > 1 {
+			return "", createError([]string{"analyze.go", "concurrencyType"}, "make_chan takes at most 1 argument, the size of the buffer", s.line)
+		}
+		if args == 1 {
+			t, err := a.typeChild(s, 2, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			if !assignable("int", t) {
+				return "", createError([]string{"analyze.go", "concurrencyType"}, "Excpected int got "+t+" for the size of a channel", s.line)
+			}
+		}
+		// Settled by settleChannel once the channel is stored
+		base, _ := typeArguments(s.varType)
+		if base == "Chan" {
+			return s.varType, nil
+		}
+		return "Chan", nil
+	case "WaitGroup":
+		if args > 0 {
+			return "", createError([]string{"analyze.go", "concurrencyType"}, "WaitGroup takes no arguments", s.line)
+		}
+		return "WaitGroup", nil
+	case "select":
+		return a.selectType(s, vars, funcs)
+	}
+	return "select case", nil
+}
+
+// A select returns the index of the case that ran, and the value received if
+// any case receives. Every channel received from must have the same element
+// type, so the value has one type
+func (a *Analyzer) selectType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	received := ""
+	defaults := 0
+
+	for i := 2; i+1 < len(s.children); i += 2 {
+		arg := s.children[i]
+		if arg.code != structureCode["EXPRESSION"] || len(arg.children) != 1 || arg.children[0].code != structureCode["ST_CALL"] || !isBuiltinCall(arg.children[0], funcs) {
+			return "", createError([]string{"analyze.go", "selectType"}, "The arguments of select must be recv, send, or default cases", s.line)
+		}
+		c := arg.children[0]
+		args := (len(c.children) - 2) / 2
+
+		switch c.children[0].text {
+		case "recv":
+			if args != 1 {
+				return "", createError([]string{"analyze.go", "selectType"}, "recv takes 1 argument, the channel", s.line)
+			}
+			elem, err := a.channelElement(c, 2, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			if received != "" && received != elem {
+				return "", createError([]string{"analyze.go", "selectType"}, "Every channel received from in a select must have the same element type, got "+received+" and "+elem, s.line)
+			}
+			received = elem
+		case "send":
+			if args != 2 {
+				return "", createError([]string{"analyze.go", "selectType"}, "send takes 2 arguments, the channel and the value", s.line)
+			}
+			elem, err := a.channelElement(c, 2, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			t, err := a.typeChild(c, 4, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			if !assignable(elem, t) {
+				return "", createError([]string{"analyze.go", "selectType"}, "Excpected "+elem+" got "+t+" sent to channel", s.line)
+			}
+		case "default":
+			defaults++
+			if args != 0 || defaults > 1 {
+				return "", createError([]string{"analyze.go", "selectType"}, "A select can have one default case, which takes no arguments", s.line)
+			}
+		default:
+			return "", createError([]string{"analyze.go", "selectType"}, "The arguments of select must be recv, send, or default cases", s.line)
+		}
+		s.children[i].children[0].varType = "select case"
+	}
+
+	if received == "" {
+		return "int", nil
+	}
+	return "tuple[int, " + received + "]", nil
+}
+
+// The element type of the channel given as a child
+func (a *Analyzer) channelElement(s Structure, i int, vars []Variable, funcs []Function) (string, error) {
+	t, err := a.typeChild(s, i, vars, funcs)
+	if err != nil {
+		return "", err
+	}
+	base, args := typeArguments(t)
+	if base != "Chan" || len(args) != 1 {
+		return "", createError([]string{"analyze.go", "channelElement"}, "Expected a channel got "+t, s.line)
+	}
+	return args[0], nil
+}
+
+// Gives a make_chan call, an empty list or dict, or a lambda, the type it is
+// stored as, as it can't be worked out from the value
+func settle(s Structure, i int, want string) {
+	base, _ := typeArguments(want)
+	expr := s.children[i]
+	if expr.code == structureCode["EXPRESSION"] && (isInteger(want) || want == "float32" || want == "float64" || want == "any") {
+		// Kept for exprType, which checks constants fit, converts ints
+		// stored as big ints, and gives float constants their type
+		s.children[i].varType = want
+	}
+	if expr.code != structureCode["EXPRESSION"] || len(expr.children) != 1 {
+		return
+	}
+	value := expr.children[0]
+	if base == "Chan" && value.code == structureCode["ST_CALL"] && value.children[0].text == "make_chan" {
+		expr.children[0].varType = want
+	}
+	if base == "list" && value.code == structureCode["LIST"] || base == "dict" && value.code == structureCode["DICT"] {
+		expr.children[0].varType = want
+	}
+	if base == "Callable" && value.code == structureCode["LAMBDA"] {
+		expr.children[0].varType = want
+	}
+}
+
+// Whether a structure contains a select
+func usesSelect(s Structure) bool {
+	if s.code == structureCode["ST_CALL"] && s.children[0].text == "select" && s.children[0].varType == "GoType" {
+		return true
+	}
+	for i := 0; i < len(s.children); i++ {
+		if usesSelect(s.children[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// The methods of a type, with T standing in for the type's argument
+var methods map[string][]Function = map[string][]Function{
+	"Chan": {
+		{name: "send", params: []string{"T"}, varType: "None"},
+		{name: "recv", params: []string{}, varType: "T"},
+		{name: "close", params: []string{}, varType: "None"},
+	},
+	"WaitGroup": {
+		{name: "add", params: []string{"int"}, varType: "None"},
+		{name: "done", params: []string{}, varType: "None"},
+		{name: "wait", params: []string{}, varType: "None"},
+	},
+	"file": {
+		{name: "read", params: []string{}, varType: "string"},
+		{name: "write", params: []string{"string"}, varType: "int"},
+		{name: "close", params: []string{}, varType: "None"},
+	},
+	"string": {
+		{name: "upper", params: []string{}, varType: "string"},
+		{name: "lower", params: []string{}, varType: "string"},
+		{name: "strip", params: []string{"string"}, varType: "string", optional: 1},
+		{name: "lstrip", params: []string{"string"}, varType: "string", optional: 1},
+		{name: "rstrip", params: []string{"string"}, varType: "string", optional: 1},
+		{name: "split", params: []string{"string"}, varType: "list[string]", optional: 1},
+		{name: "join", params: []string{"list[string]"}, varType: "string"},
+		{name: "startswith", params: []string{"string"}, varType: "bool"},
+		{name: "endswith", params: []string{"string"}, varType: "bool"},
+		{name: "replace", params: []string{"string", "string"}, varType: "string"},
+		{name: "find", params: []string{"string"}, varType: "int"},
+		{name: "count", params: []string{"string"}, varType: "int"},
+		{name: "isdigit", params: []string{}, varType: "bool"},
+		{name: "isalpha", params: []string{}, varType: "bool"},
+		{name: "isspace", params: []string{}, varType: "bool"},
+	},
+}
+
+// Finds a method of a type, from its class or the built in methods
+func (a *Analyzer) method(t string, name string) (Function, bool) {
+	options := methods[t]
+	if class, exists := a.classes[t]; exists {
+		options = class.methods
+	}
+	for i := 0; i < len(options); i++ {
+		if options[i].name == name {
+			return options[i], true
+		}
+	}
+	return Function{}, false
+}
+
+// Finds the method a call is to, such as ch.send(v), with its generic types
+// filled in. The type of the receiver is kept on the ATTRIBUTE for the
+// emitter. Receivers other than variables, such as strings and the results
+// of calls, are typed like any other expression
+func (a *Analyzer) findMethod(s Structure, vars []Variable, funcs []Function) (Function, error) {
+	callee := s.children[0]
+	name := ""
+	for i := 0; i < len(callee.children); i++ {
+		name += callee.children[i].text
+	}
+
+	receiver := ""
+	if callee.children[0].code == structureCode["IDENTIFIER"] {
+		variable, exists := findVariable(callee.children[0].text, vars)
+		if !exists || len(callee.children) != 3 {
+			return Function{}, createError([]string{"analyze.go", "findMethod"}, "\""+name+"\" doesn't exist", s.line)
+		}
+		receiver = variable.varType
+	} else {
+		t, err := a.typeChild(callee, 0, vars, funcs)
+		if err != nil {
+			return Function{}, err
+		}
+		receiver = t
+	}
+	s.children[0].varType = receiver
+
+	base, typeArgs := typeArguments(receiver)
+	method, found := a.method(base, callee.children[2].text)
+	if !found {
+		return Function{}, createError([]string{"analyze.go", "findMethod"}, receiver+" has no method \""+callee.children[2].text+"\"", s.line)
+	}
+
+	// T stands in for the type's argument
+	generic := func(t string) string {
+		if t == "T" && len(typeArgs) == 1 {
+			return typeArgs[0]
+		}
+		return t
+	}
+	method.name = name
+	method.params = append([]string{}, method.params...)
+	for i := 0; i < len(method.params); i++ {
+		method.params[i] = generic(method.params[i])
+	}
+	method.varType = generic(method.varType)
+
+	return method, nil
+}
+
+func (a *Analyzer) methodType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	method, err := a.findMethod(s, vars, funcs)
+	return method.varType, err
+}
+
+// Checks the arguments of a method call, which have been resolved by
+// resolveCall if the method is from a class
+func (a *Analyzer) checkMethodCall(s Structure, vars []Variable, funcs []Function) error {
+	method, err := a.findMethod(s, vars, funcs)
+	if err != nil {
+		return err
+	}
+
+	args := (len(s.children) - 2) / 2
+	if (args > len(method.params) || args < len(method.params)-method.optional) && !method.variadic {
+		return createError([]string{"analyze.go", "checkMethodCall"}, "\""+method.name+"\" takes "+strconv.Itoa(len(method.params))+" arguments, got "+strconv.Itoa(args), s.line)
+	}
+	for i := 0; i < args; i++ {
+		arg := s.children[2+i*2]
+		if arg.code == structureCode["KEYWORD_ARG"] {
+			return createError([]string{"analyze.go", "checkMethodCall"}, "\""+method.name+"\" doesn't take keyword arguments", s.line)
+		}
+
+		want := method.params[len(method.params)-1]
+		if i < len(method.params)-1 || !method.variadic {
+			want = method.params[i]
+		}
+		settle(s, 2+i*2, want)
+		t, err := a.typeChild(s, 2+i*2, vars, funcs)
+		if err != nil {
+			return err
+		}
+		if !assignable(want, t) {
+			return createError([]string{"analyze.go", "checkMethodCall"}, "Excpected "+want+" got "+t+" in call to \""+method.name+"\"", s.line)
+		}
+	}
+
+	// Python raises a ValueError for this
+	if separator, constant := constantString(s.children[2]); s.children[0].varType == "string" && strings.HasSuffix(method.name, ".split") && args == 1 && constant && separator == "" {
+		return createError([]string{"analyze.go", "checkMethodCall"}, "empty separator", s.line)
+	}
+	return nil
+}
+
+// Reads the fields and methods of a class from its body
+func classSignature(s Structure) (Class, error) {
+	c := Class{name: s.children[1].text}
+
+	body := s.children[3]
+	for i := 0; i < len(body.children); i++ {
+		child := body.children[i]
+		switch child.code {
+		case structureCode["NEWLINE"], structureCode["ANTI_COLON"], structureCode["COMMENT_ONE"], structureCode["COMMENT_MULTI"], structureCode["ST_PASS"]:
+		case structureCode["ST_DECLARATION"]:
+			c.fields = append(c.fields, Variable{child.children[0].text, child.children[2].text})
+			c.defaults = append(c.defaults, child.children[4])
+		case structureCode["ST_FUNCTION"]:
+			f, err := functionSignature(child)
+			if err != nil {
+				return c, err
+			}
+			if len(f.names) == 0 || f.names[0] != "self" || f.params[0] != c.name {
+				return c, createError([]string{"analyze.go", "classSignature"}, "The first parameter of the method \""+f.name+"\" must be self", child.line)
+			}
+			f.params = f.params[1:]
+			f.names = f.names[1:]
+			f.defaults = f.defaults[1:]
+			f.positional--
+			c.methods = append(c.methods, f)
+		default:
+			return c, createError([]string{"analyze.go", "classSignature"}, "The body of a class can only have fields and methods", child.line)
+		}
+	}
+
+	return c, nil
+}
+
+// Checks the fields and methods of a class
+func (a *Analyzer) analyzeClass(s Structure, vars []Variable, funcs []Function) error {
+	c := a.classes[s.children[1].text]
+
+	for i := 0; i < len(c.fields); i++ {
+		if !isConstant(c.defaults[i]) {
+			return createError([]string{"analyze.go", "analyzeClass"}, "The value of the field \""+c.fields[i].name+"\" must be a literal", s.line)
+		}
+		// Kept for exprType, which checks constants fit, and converts ints
+		// stored as big ints
+		c.defaults[i].varType = c.fields[i].varType
+		t, err := a.exprType(c.defaults[i], []Variable{}, []Function{})
+		if err != nil {
+			return err
+		}
+		if !assignable(c.fields[i].varType, t) {
+			return createError([]string{"analyze.go", "analyzeClass"}, "Excpected "+c.fields[i].varType+" got "+t+" for the field \""+c.fields[i].name+"\"", s.line)
+		}
+	}
+
+	body := s.children[3]
+	for i := 0; i < len(body.children); i++ {
+		method := body.children[i]
+		if method.code != structureCode["ST_FUNCTION"] {
+			continue
+		}
+		name := method.children[1].text
+		f, _ := a.method(c.name, name)
+
+		// Methods have no error to return an exception with
+		if mayRaiseBesidesBuiltins(method.children[len(method.children)-1], a.raising) {
+			return createError([]string{"analyze.go", "analyzeClass"}, "The method \""+c.name+"."+name+"\" may raise, which methods can't do", method.line)
+		}
+
+		// The emitter calls __exit__ with None for each argument
+		if name == "__exit__" && !f.variadic {
+			valid := len(f.params) == 3
+			for j := 0; j < len(f.params); j++ {
+				valid = valid && f.params[j] == "any"
+			}
+			if !valid {
+				return createError([]string{"analyze.go", "analyzeClass"}, "__exit__ must take three any parameters, or *args: any", method.line)
+			}
+		}
+		if (name == "__init__" || name == "__exit__") && f.varType != "None" {
+			return createError([]string{"analyze.go", "analyzeClass"}, name+" must return None", method.line)
+		}
+
+		err := a.analyze(method, vars, funcs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Calls that for loops turn into Go loops rather than call, unless the
+// program defines its own function with the same name
+var loopCalls []string = []string{"range", "enumerate", "zip", "reversed"}
+
+func isLoopCall(s Structure, funcs []Function) bool {
+	if s.code != structureCode["ST_CALL"] || s.children[0].code != structureCode["FUNC_NAME"] {
+		return false
+	}
+	if _, defined := findFunction(s.children[0].text, funcs); defined && s.children[0].text != "range" {
+		return false
+	}
+	for i := 0; i < len(loopCalls); i++ {
+		if loopCalls[i] == s.children[0].text {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks a for loop, giving each name the type of the value it takes
+func (a *Analyzer) analyzeFor(s Structure, vars []Variable, funcs []Function) error {
+	names := []string{}
+	i := 1
+	for ; ; i += 2 {
+		names = append(names, s.children[i].text)
+		if s.children[i+1].code != structureCode["SEP"] {
+			break
+		}
+	}
+
+	types, err := a.iterate(s, i+2, vars, funcs)
+	if err != nil {
+		return err
+	}
+	if len(types) != len(names) {
+		return createError([]string{"analyze.go", "analyzeFor"}, "The loop gives "+strconv.Itoa(len(types))+" values, but "+strconv.Itoa(len(names))+" names were given", s.line)
+	}
+
+	inner := vars
+	for j := 0; j < len(names); j++ {
+		s.children[1+j*2].varType = types[j]
+		inner = append(inner, Variable{names[j], types[j]})
+	}
+	return a.analyzeLoop(s, i+4, inner, vars, funcs)
+}
+
+// Checks the body of a loop, with the variables the loop gives it, and the
+// else clause after it
+func (a *Analyzer) analyzeLoop(s Structure, body int, inner []Variable, vars []Variable, funcs []Function) error {
+	a.loops++
+	err := a.analyze(s.children[body], inner, funcs)
+	a.loops--
+	if err != nil {
+		return err
+	}
+	if body+1 < len(s.children) {
+		return a.analyze(s.children[body+1], vars, funcs)
+	}
+	return nil
+}
+
+// The types of the values a loop over an iterable gives. Calls to range,
+// enumerate, zip and reversed, and the items, keys and values of a dict,
+// have their callee marked with "loop" for the emitter
+func (a *Analyzer) iterate(s Structure, i int, vars []Variable, funcs []Function) ([]string, error) {
+	expr := s.children[i]
+	if len(expr.children) != 1 || expr.children[0].code != structureCode["ST_CALL"] {
+		return a.iterableType(s, i, vars, funcs)
+	}
+	call := expr.children[0]
+
+	// The items of a dict
+	if call.children[0].code == structureCode["ATTRIBUTE"] && len(call.children[0].children) == 3 && len(call.children) == 3 {
+		receiver, exists := findVariable(call.children[0].children[0].text, vars)
+		base, args := typeArguments(receiver.varType)
+		if exists && base == "dict" {
+			switch call.children[0].children[2].text {
+			case "items":
+				call.children[0].varType = "loop"
+				return args, nil
+			case "keys":
+				call.children[0].varType = "loop"
+				return args[:1], nil
+			case "values":
+				call.children[0].varType = "loop"
+				return args[1:], nil
+			}
+		}
+	}
+
+	if !isLoopCall(call, funcs) {
+		return a.iterableType(s, i, vars, funcs)
+	}
+	call.children[0].varType = "loop"
+
+	name := call.children[0].text
+	args := (len(call.children) - 2) / 2
+	for j := 2; j+1 < len(call.children); j += 2 {
+		if call.children[j].code == structureCode["KEYWORD_ARG"] {
+			return nil, createError([]string{"analyze.go", "iterate"}, name+" doesn't take keyword arguments", s.line)
+		}
+		if name == "reversed" && isRange(call.children[j]) {
+			continue
+		}
+		err := a.analyze(call.children[j], vars, funcs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch name {
+	case "range":
+		t, err := a.rangeType(call, vars, funcs)
+		if err != nil {
+			return nil, err
+		}
+		return []string{t}, nil
+	case "enumerate":
+		if args < 1 || args > 2 {
+			return nil, createError([]string{"analyze.go", "iterate"}, "enumerate takes an iterable and an optional start", s.line)
+		}
+		types, err := a.iterableType(call, 2, vars, funcs)
+		if err != nil {
+			return nil, err
+		}
+		if args == 2 {
+			t, err := a.typeChild(call, 4, vars, funcs)
+			if err != nil {
+				return nil, err
+			}
+			if !assignable("int", t) {
+				return nil, createError([]string{"analyze.go", "iterate"}, "Excpected int got "+t+" for the start of enumerate", s.line)
+			}
+		}
+		if a.settings.bigInts {
+			return []string{"int64", types[0]}, nil
+		}
+		return []string{"int", types[0]}, nil
+	case "zip":
+		if args < 1 {
+			return nil, createError([]string{"analyze.go", "iterate"}, "zip needs at least 1 iterable", s.line)
+		}
+		types := []string{}
+		for j := 2; j+1 < len(call.children); j += 2 {
+			t, err := a.indexable(call, j, vars, funcs)
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, t)
+		}
+		return types, nil
+	}
+
+	// reversed
+	if args != 1 {
+		return nil, createError([]string{"analyze.go", "iterate"}, "reversed takes 1 argument", s.line)
+	}
+	if isRange(call.children[2]) {
+		inner := call.children[2].children[0]
+		if len(inner.children) > 7 {
+			return nil, createError([]string{"analyze.go", "iterate"}, "reversed only takes a range without a step", s.line)
+		}
+		inner.children[0].varType = "loop"
+		for j := 2; j+1 < len(inner.children); j += 2 {
+			err := a.analyze(inner.children[j], vars, funcs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		t, err := a.rangeType(inner, vars, funcs)
+		if err != nil {
+			return nil, err
+		}
+		return []string{t}, nil
+	}
+	t, err := a.indexable(call, 2, vars, funcs)
+	if err != nil {
+		return nil, err
+	}
+	return []string{t}, nil
+}
+
+// Whether an expression is only a call to range
+func isRange(s Structure) bool {
+	return len(s.children) == 1 && s.children[0].code == structureCode["ST_CALL"] && s.children[0].children[0].text == "range"
+}
+
+// The type of the value looping over a string, list or dict gives
+func (a *Analyzer) iterableType(s Structure, i int, vars []Variable, funcs []Function) ([]string, error) {
+	err := a.analyze(s.children[i], vars, funcs)
+	if err != nil {
+		return nil, err
+	}
+	t, err := a.typeChild(s, i, vars, funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	base, args := typeArguments(t)
+	switch {
+	case t == "string":
+		return []string{"string"}, nil
+	case base == "list" && len(args) == 1, base == "dict" && len(args) == 2:
+		return args[:1], nil
+	}
+	return nil, createError([]string{"analyze.go", "iterableType"}, t+" can't be looped over", s.line)
+}
+
+// The element type of a string or list, which zip and reversed need, as
+// they index into what they loop over
+func (a *Analyzer) indexable(s Structure, i int, vars []Variable, funcs []Function) (string, error) {
+	t, err := a.typeChild(s, i, vars, funcs)
+	if err != nil {
+		return "", err
+	}
+
+	base, args := typeArguments(t)
+	if t == "string" {
+		return "string", nil
+	}
+	if base == "list" && len(args) == 1 {
+		return args[0], nil
+	}
+	return "", createError([]string{"analyze.go", "indexable"}, t+" can't be indexed, so it can't be used with "+s.children[0].text, s.line)
+}
+
+// The type of the numbers a range gives, from the types of its arguments
+func (a *Analyzer) rangeType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	args := (len(s.children) - 2) / 2
+	if args < 1 || args > 3 {
+		return "", createError([]string{"analyze.go", "rangeType"}, "range takes 1 to 3 arguments, got "+strconv.Itoa(args), s.line)
+	}
+
+	t := ""
+	for i := 2; i+1 < len(s.children); i += 2 {
+		if s.children[i].code == structureCode["KEYWORD_ARG"] {
+			return "", createError([]string{"analyze.go", "rangeType"}, "range doesn't take keyword arguments", s.line)
+		}
+		arg, err := a.typeChild(s, i, vars, funcs)
+		if err != nil {
+			return "", err
+		}
+		if arg != "untyped int" && !isInteger(arg) {
+			return "", createError([]string{"analyze.go", "rangeType"}, "range takes integers, got "+arg, s.line)
+		}
+
+		if t == "" {
+			t = arg
+			continue
+		}
+		combined, valid := combineTypes(t, arg)
+		if !valid {
+			return "", createError([]string{"analyze.go", "rangeType"}, "Mismatched types "+t+" and "+arg+" in range", s.line)
+		}
+		t = combined
+	}
+
+	if args == 3 && len(s.children[6].children) == 1 && isConstant(s.children[6]) {
+		step, _ := strconv.Atoi(s.children[6].children[0].text)
+		if step == 0 {
+			return "", createError([]string{"analyze.go", "rangeType"}, "range() arg 3 must not be zero", s.line)
+		}
+	}
+	// Big ints can't count a Go loop, so ranges of them count with an int64
+	if a.settings.bigInts && defaultType(t) == "int" {
+		return "int64", nil
+	}
+	return defaultType(t), nil
+}
@@ -0,0 +1,764 @@
+package main
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// An expression arranged by precedence, as the parser keeps the operands
+// and operators of an expression in one flat list
+type Operation struct {
+	index    int // Index of the operand, or of the operator, in the expression
+	operator string
+	left     *Operation
+	right    *Operation
+}
+
+// A part of an expression that has been emitted
+type Operand struct {
+	text    string
+	varType string
+	level   int // How tightly the Go operator at the top binds, 3 for an operand
+}
+
+// How tightly each operator binds
+var precedence map[string]int = map[string]int{
+	"+":  1,
+	"-":  1,
+	"*":  2,
+	"/":  2,
+	"//": 2,
+	"%":  2,
+	"**": 3,
+}
+
+func arrange(s Structure) *Operation {
+	i := 0
+	return arrangeFrom(s, &i, 1)
+}
+
+// Reads operands from i, along with any operators that bind at least as
+// tightly as level. ** binds to the right, the rest to the left
+func arrangeFrom(s Structure, i *int, level int) *Operation {
+	o := &Operation{index: *i}
+	*i++
+	for *i < len(s.children) && precedence[s.children[*i].text] >= level {
+		op := s.children[*i].text
+		index := *i
+		*i++
+
+		next := precedence[op] + 1
+		if op == "**" {
+			next = precedence[op]
+		}
+		o = &Operation{index: index, operator: op, left: o, right: arrangeFrom(s, i, next)}
+	}
+	return o
+}
+
+func isFloat(t string) bool {
+	return t == "float32" || t == "float64" || t == "untyped float"
+}
+
+// Whether a type is an int of any size, or an untyped int constant
+func isIntegral(t string) bool {
+	return t == "untyped int" || isInteger(t)
+}
+
+// The type of an arithmetic operation. Python turns ints into floats when
+// they are mixed, and / always gives a float, but Go semantics keep Go's
+// rules. Big ints take in any fixed width int they are mixed with
+func arithmeticType(op string, left, right string, settings Settings, line int) (string, error) {
+	numeric := (isIntegral(left) || isFloat(left)) && (isIntegral(right) || isFloat(right))
+	if !numeric {
+		if op == "+" && left == "string" && right == "string" {
+			return "string", nil
+		}
+		if left == right || left == "untyped int" || left == "untyped float" {
+			return "", createError([]string{"arithmetic.go", "arithmeticType"}, op+" can't be used on "+right, line)
+		}
+		return "", createError([]string{"arithmetic.go", "arithmeticType"}, op+" can't be used on "+left, line)
+	}
+
+	t, valid := combineTypes(left, right)
+	if !valid && settings.bigInts && (left == "int" && isInteger(right) || isInteger(left) && right == "int") {
+		t, valid = "int", true
+	}
+	if !valid && !settings.goSemantics {
+		if isFloat(left) && isIntegral(right) {
+			t, valid = defaultType(left), true
+		} else if isIntegral(left) && isFloat(right) {
+			t, valid = defaultType(right), true
+		}
+	}
+	if !valid {
+		return "", createError([]string{"arithmetic.go", "arithmeticType"}, "Mismatched types "+left+" and "+right+" in expression", line)
+	}
+
+	if settings.goSemantics {
+		return t, nil
+	}
+	switch {
+	case op == "/" && t == "untyped int":
+		return "untyped float", nil
+	case op == "/" && isIntegral(t):
+		return "float64", nil
+	case t == "untyped float" && (op == "//" || op == "%" || op == "**"):
+		// These need a function, so the result can't be a constant
+		return "float64", nil
+	}
+	return t, nil
+}
+
+// The type of each part of an operation, from the types the analyzer gave
+// the operands
+func operationType(s Structure, o *Operation, settings Settings) (string, error) {
+	if o.operator == "" {
+		return s.children[o.index].varType, nil
+	}
+	left, err := operationType(s, o.left, settings)
+	if err != nil {
+		return "", err
+	}
+	right, err := operationType(s, o.right, settings)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := arithmeticType(o.operator, left, right, settings, s.line)
+	if err != nil {
+		return "", err
+	}
+	err = checkDivisor(s, o.operator, o.right, isFloat(left) || isFloat(right))
+	if err != nil {
+		return "", err
+	}
+	if zeroDivision[o.operator][0] != "" && !settings.goSemantics && !nonZeroConstant(s, o.right) {
+		s.children[o.index].varType = "raises"
+	}
+
+	// Go won't compile a constant that doesn't fit the type it is used as
+	if left == "untyped int" && fixedWidth(t, settings) {
+		err = checkConstant(s, o.left, t)
+	}
+	if right == "untyped int" && fixedWidth(t, settings) && err == nil {
+		err = checkConstant(s, o.right, t)
+	}
+	if err != nil {
+		return "", err
+	}
+	if settings.goSemantics || t != "untyped int" {
+		return t, nil
+	}
+
+	// Constants are worked out while compiling, unless they give a float
+	if o.operator == "//" || o.operator == "%" || o.operator == "**" {
+		_, err = constantInt(s, o)
+		if err != nil && o.operator == "**" {
+			return "float64", nil
+		}
+	}
+	return t, err
+}
+
+// Whether a type is an int that can overflow, which is any but a big int
+func fixedWidth(t string, settings Settings) bool {
+	return isInteger(t) && !(settings.bigInts && t == "int")
+}
+
+// The number of bits in each fixed width int
+var intBits map[string]uint = map[string]uint{
+	"int":     64,
+	"int8":    8,
+	"int16":   16,
+	"int32":   32,
+	"int64":   64,
+	"uint":    64,
+	"uint8":   8,
+	"uint16":  16,
+	"uint32":  32,
+	"uint64":  64,
+	"uintptr": 64,
+	"byte":    8,
+	"rune":    32,
+}
+
+// Checks that an operation made only of int literals fits in t
+func checkConstant(s Structure, o *Operation, t string) error {
+	value, err := constantInt(s, o)
+	if err != nil {
+		return err
+	}
+
+	bits := intBits[t]
+	fits := value.Sign() >= 0 && value.BitLen() <= int(bits)
+	if !strings.HasPrefix(t, "u") && t != "byte" {
+		limit := new(big.Int).Lsh(big.NewInt(1), bits-1)
+		fits = value.Cmp(new(big.Int).Neg(limit)) >= 0 && value.Cmp(limit) < 0
+	}
+	if !fits {
+		return createError([]string{"arithmetic.go", "checkConstant"}, "The constant "+value.String()+" overflows "+t, s.line)
+	}
+	return nil
+}
+
+// The value of an operation made only of int literals, worked out the way
+// Python would
+func constantInt(s Structure, o *Operation) (*big.Int, error) {
+	if o.operator == "" {
+		value, valid := new(big.Int).SetString(strings.ReplaceAll(s.children[o.index].text, "_", ""), 10)
+		if !valid {
+			return nil, createError([]string{"arithmetic.go", "constantInt"}, "Expected an int got "+s.children[o.index].text, s.line)
+		}
+		return value, nil
+	}
+
+	left, err := constantInt(s, o.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := constantInt(s, o.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.operator {
+	case "+":
+		return left.Add(left, right), nil
+	case "-":
+		return left.Sub(left, right), nil
+	case "*":
+		return left.Mul(left, right), nil
+	case "**":
+		if right.Sign() < 0 {
+			return nil, createError([]string{"arithmetic.go", "constantInt"}, "A negative power of an int is a float", s.line)
+		}
+		// -2 ** 2 is -(2 ** 2), as the minus binds more loosely
+		if o.left.operator == "" && left.Sign() < 0 {
+			power := new(big.Int).Exp(left.Neg(left), right, nil)
+			return power.Neg(power), nil
+		}
+		return left.Exp(left, right, nil), nil
+	}
+
+	if right.Sign() == 0 {
+		return nil, createError([]string{"arithmetic.go", "constantInt"}, "integer division or modulo by zero", s.line)
+	}
+	quotient, remainder := new(big.Int).QuoRem(left, right, new(big.Int))
+	if remainder.Sign() != 0 && remainder.Sign() != right.Sign() {
+		quotient.Sub(quotient, big.NewInt(1))
+		remainder.Add(remainder, right)
+	}
+	if o.operator == "//" {
+		return quotient, nil
+	}
+	return remainder, nil
+}
+
+// The value of an operation made only of int and float literals, worked out
+// exactly, and whether it is one
+func constantRat(s Structure, o *Operation) (*big.Rat, bool) {
+	if o.operator == "//" || o.operator == "%" || o.operator == "**" {
+		// Only ints are constant with these
+		value, err := constantInt(s, o)
+		if err != nil {
+			return nil, false
+		}
+		return new(big.Rat).SetInt(value), true
+	}
+	if o.operator == "" {
+		operand := s.children[o.index]
+		if operand.varType != "untyped int" && operand.varType != "untyped float" {
+			return nil, false
+		}
+		return new(big.Rat).SetString(strings.ReplaceAll(operand.text, "_", ""))
+	}
+
+	left, valid := constantRat(s, o.left)
+	if !valid {
+		return nil, false
+	}
+	right, valid := constantRat(s, o.right)
+	if !valid {
+		return nil, false
+	}
+	switch o.operator {
+	case "+":
+		return left.Add(left, right), true
+	case "-":
+		return left.Sub(left, right), true
+	case "*":
+		return left.Mul(left, right), true
+	}
+	if right.Sign() == 0 {
+		return nil, false
+	}
+	return left.Quo(left, right), true
+}
+
+// Checks an operation doesn't divide by a constant zero, which Python raises
+// ZeroDivisionError for, and Go either won't compile, or gives infinity for
+func checkDivisor(s Structure, op string, divisor *Operation, float bool) error {
+	if zeroDivision[op][0] == "" {
+		return nil
+	}
+	value, constant := constantRat(s, divisor)
+	if !constant || value.Sign() != 0 {
+		return nil
+	}
+	return createError([]string{"arithmetic.go", "checkDivisor"}, zeroDivisionMessage(op, float), s.line)
+}
+
+// Whether part of an expression is a constant other than zero, which is
+// safe to divide by
+func nonZeroConstant(s Structure, o *Operation) bool {
+	value, constant := constantRat(s, o)
+	return constant && value.Sign() != 0
+}
+
+// What Python's ZeroDivisionError says for each operator, when dividing
+// ints, and when dividing floats
+var zeroDivision map[string][2]string = map[string][2]string{
+	"/":      {"division by zero", "float division by zero"},
+	"//":     {"integer division or modulo by zero", "float floor division by zero"},
+	"%":      {"integer modulo by zero", "float modulo"},
+	"divmod": {"integer division or modulo by zero", "float divmod()"},
+}
+
+func zeroDivisionMessage(op string, float bool) string {
+	if float {
+		return zeroDivision[op][1]
+	}
+	return zeroDivision[op][0]
+}
+
+// Whether an int constant is too big for Go, which big ints can hold
+func overflows(s Structure) bool {
+	value, err := constantInt(s, arrange(s))
+	return err == nil && !value.IsInt64()
+}
+
+// Whether a type has a dict with int keys, which can't be big ints
+func bigKeys(t string) bool {
+	base, args := typeArguments(t)
+	if base == "dict" && args[0] == "int" {
+		return true
+	}
+	for i := 0; i < len(args); i++ {
+		if bigKeys(args[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Python's divmod, which gives the results of // and % together
+func (a *Analyzer) divmodType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	types := []string{}
+	for i := 2; i < 6; i += 2 {
+		t, err := a.typeChild(s, i, vars, funcs)
+		if err != nil {
+			return "", err
+		}
+		types = append(types, t)
+	}
+
+	t, err := arithmeticType("//", types[0], types[1], a.settings, s.line)
+	if err != nil {
+		return "", err
+	}
+	t = defaultType(t)
+	return "tuple[" + t + ", " + t + "]", nil
+}
+
+// Conversions between numeric types, which are legal in Go the same as in
+// Python. int and float also parse strings, as Python's do
+func (a *Analyzer) conversionType(s Structure, name string, vars []Variable, funcs []Function) (string, error) {
+	t, err := a.typeChild(s, 2, vars, funcs)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case t == "string" && (s.children[0].text == "int" || s.children[0].text == "float"):
+		return name, nil
+	case t == "string":
+		return "", createError([]string{"arithmetic.go", "conversionType"}, "A string can't be converted to "+name+", it has to be parsed with int() or float() first", s.line)
+	case !isIntegral(t) && !isFloat(t):
+		return "", createError([]string{"arithmetic.go", "conversionType"}, t+" can't be converted to "+name, s.line)
+	}
+	if t == "untyped int" && fixedWidth(name, a.settings) {
+		return name, checkConstant(s.children[2], arrange(s.children[2]), name)
+	}
+	return name, nil
+}
+
+// A conversion, which is Go's unless a big int or a string is involved.
+// Parsing a string gives an error as well, as it is a raising call. Go won't
+// truncate a float constant, so it is truncated by math.Trunc
+func (e *Emitter) emitConversion(ast Structure) (string, error) {
+	temp, err := e.emit(ast.children[2])
+	if err != nil {
+		return "", err
+	}
+	arg := Operand{strings.TrimSpace(temp), ast.children[2].varType, 3}
+	t := ast.varType
+
+	switch {
+	case arg.varType == t:
+		return arg.text, nil
+	case arg.varType == "string" && e.settings.bigInts && t == "int":
+		e.helper("pyBigAtoi")
+		return "pyBigAtoi(" + arg.text + ")", nil
+	case arg.varType == "string" && t == "float64":
+		e.helper("pyAtof")
+		return "pyAtof(" + arg.text + ")", nil
+	case arg.varType == "string":
+		e.helper("pyAtoi")
+		return "pyAtoi(" + arg.text + ")", nil
+	case e.settings.bigInts && t == "int" && arg.varType == "float32":
+		e.helper("pyBigTrunc")
+		return "pyBigTrunc(float64(" + arg.text + "))", nil
+	case e.settings.bigInts && t == "int" && isFloat(arg.varType):
+		e.helper("pyBigTrunc")
+		return "pyBigTrunc(" + arg.text + ")", nil
+	case e.settings.bigInts && (t == "int" || arg.varType == "int" && isFloat(t)):
+		return e.convert(arg, t).text, nil
+	case e.settings.bigInts && arg.varType == "int" && strings.HasPrefix(t, "u"):
+		return t + "(" + arg.text + ".Uint64())", nil
+	case e.settings.bigInts && arg.varType == "int":
+		return t + "(" + arg.text + ".Int64())", nil
+	case arg.varType == "untyped float" && isInteger(t):
+		e.require("math")
+		return t + "(math.Trunc(" + arg.text + "))", nil
+	}
+	return t + "(" + arg.text + ")", nil
+}
+
+// divmod as a runtime helper, with ints made into floats if they are mixed
+func (e *Emitter) emitDivmod(ast Structure) (string, error) {
+	_, types := typeArguments(ast.varType)
+	args := []string{}
+	for i := 2; i < 6; i += 2 {
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return "", err
+		}
+		arg := e.convert(Operand{strings.TrimSpace(temp), ast.children[i].varType, 3}, types[0])
+		args = append(args, arg.text)
+	}
+
+	if dividesByZero(ast) {
+		args[1] = e.divisor(Operand{args[1], types[0], 3}, "divmod", isFloat(types[0]), ast.line).text
+	}
+
+	name := "pyDivmod"
+	if isFloat(types[0]) {
+		name = "pyFloatDivmod"
+	} else if e.settings.bigInts && types[0] == "int" {
+		name = "pyBigDivmod"
+	}
+	e.helper(name)
+	return name + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// Expressions with operators, arranged by precedence so each operator can
+// be given Python's meaning
+func (e *Emitter) emitExpression(ast Structure) (string, error) {
+	float := "float64"
+	if ast.varType == "float32" {
+		float = "float32"
+	}
+	o, err := e.emitOperation(ast, arrange(ast), float)
+	if err != nil {
+		return "", err
+	}
+	// The analyzer settles values stored as big ints to int
+	if e.settings.bigInts && ast.varType == "int" {
+		o = e.convert(o, "int")
+	}
+	return o.text, nil
+}
+
+// Float constants are worked out in the float type given, as Go works out a
+// typed constant one step at a time, rounding the way Python's floats do. Go
+// works out untyped constants exactly
+func (e *Emitter) emitOperation(s Structure, o *Operation, float string) (Operand, error) {
+	if o.operator == "" {
+		temp, err := e.emit(s.children[o.index])
+		return Operand{strings.TrimSpace(temp), s.children[o.index].varType, 3}, err
+	}
+
+	t, err := operationType(s, o, e.settings)
+	if err != nil {
+		return Operand{}, err
+	}
+	if t == "untyped int" && (o.operator == "**" || e.settings.bigInts || !e.settings.goSemantics && (o.operator == "//" || o.operator == "%")) {
+		value, err := constantInt(s, o)
+		if err != nil {
+			return Operand{}, err
+		}
+		return Operand{value.String(), t, 3}, nil
+	}
+
+	if isFloat(t) && t != "untyped float" {
+		float = t
+	}
+	left, err := e.emitOperation(s, o.left, float)
+	if err != nil {
+		return Operand{}, err
+	}
+	right, err := e.emitOperation(s, o.right, float)
+	if err != nil {
+		return Operand{}, err
+	}
+	if s.children[o.index].varType == "raises" {
+		right = e.divisor(right, o.operator, isFloat(left.varType) || isFloat(right.varType), s.line)
+	}
+	if t == "untyped float" && !e.settings.goSemantics {
+		left, right, t = typedFloat(left, float), typedFloat(right, float), float
+	}
+
+	// -2 ** 2 is -(2 ** 2), as the minus binds more loosely
+	if o.operator == "**" && o.left.operator == "" && strings.HasPrefix(left.text, "-") {
+		left.text = left.text[1:]
+		power := e.arithmetic("**", left, right, t, s.line)
+		if e.settings.bigInts && t == "int" {
+			power.text = "new(big.Int).Neg(" + power.text + ")"
+			return power, nil
+		}
+		power.text, power.level = "-"+power.text, 1
+		return power, nil
+	}
+	return e.arithmetic(o.operator, left, right, t, s.line), nil
+}
+
+// A divisor that may be zero, kept in a variable placed before the statement
+// it is in, so a ZeroDivisionError can be raised before dividing by it
+func (e *Emitter) divisor(o Operand, op string, float bool, line int) Operand {
+	e.count++
+	temp := "pyTmp" + strconv.Itoa(e.count)
+	zero := temp + " == 0"
+	if e.settings.bigInts && o.varType == "int" {
+		zero = temp + ".Sign() == 0"
+	}
+	e.helper("ZeroDivisionError")
+	raise := e.throw("&ZeroDivisionError{\""+zeroDivisionMessage(op, float)+"\"}", line)
+	e.pre = append(e.pre, "\n"+temp+" := "+o.text+"\nif "+zero+" {"+raise+"}\n")
+	return Operand{temp, o.varType, 3}
+}
+
+// A constant as a typed float, which other operands are left as
+func typedFloat(o Operand, float string) Operand {
+	if !strings.HasPrefix(o.varType, "untyped") {
+		return o
+	}
+	return Operand{float + "(" + o.text + ")", float, 3}
+}
+
+// One operator applied to two operands, giving a value of type t. Checked
+// arithmetic passes the line to a helper, to panic with if it overflows
+func (e *Emitter) arithmetic(op string, left, right Operand, t string, line int) Operand {
+	goSemantics := e.settings.goSemantics
+	left = e.convert(left, t)
+	right = e.convert(right, t)
+	if e.settings.bigInts && t == "int" {
+		return e.bigArithmetic(op, left, right)
+	}
+	if e.settings.checkedArith && fixedWidth(t, e.settings) && checkedHelpers[op] != "" && !(e.settings.goSemantics && op == "//") {
+		e.helper(checkedHelpers[op])
+		return Operand{checkedHelpers[op] + "(" + left.text + ", " + right.text + ", " + strconv.Itoa(line) + ")", t, 3}
+	}
+
+	infix := func(goOp string) Operand {
+		level := precedence[goOp]
+		return Operand{parenthesize(left, level, false) + " " + goOp + " " + parenthesize(right, level, true), t, level}
+	}
+	call := func(name string) Operand {
+		return Operand{name + "(" + left.text + ", " + right.text + ")", t, 3}
+	}
+	// The math package only works on float64
+	float64Call := func(name string) Operand {
+		if t == "float32" {
+			left.text, right.text = "float64("+left.text+")", "float64("+right.text+")"
+			result := call(name)
+			result.text = "float32(" + result.text + ")"
+			return result
+		}
+		return call(name)
+	}
+
+	switch op {
+	case "+", "-", "*":
+		return infix(op)
+	case "/":
+		return infix("/")
+	case "**":
+		if isFloat(t) {
+			e.require("math")
+			return float64Call("math.Pow")
+		}
+		e.helper("pyIntPow")
+		return call("pyIntPow")
+	}
+
+	if goSemantics && op == "//" && !isFloat(t) {
+		return infix("/")
+	}
+	if isFloat(t) && op == "//" {
+		e.require("math")
+		quotient := infix("/")
+		if t == "float32" {
+			return Operand{"float32(math.Floor(float64(" + quotient.text + ")))", t, 3}
+		}
+		return Operand{"math.Floor(" + quotient.text + ")", t, 3}
+	}
+	if isFloat(t) && goSemantics {
+		e.require("math")
+		return float64Call("math.Mod")
+	}
+	if isFloat(t) {
+		e.helper("pyFloatMod")
+		return float64Call("pyFloatMod")
+	}
+	if goSemantics {
+		return infix("%")
+	}
+	if op == "//" {
+		e.helper("pyFloorDiv")
+		return call("pyFloorDiv")
+	}
+	e.helper("pyMod")
+	return call("pyMod")
+}
+
+// An int operand used where a float is needed, or where a big int is.
+// Other constants are converted by Go
+func (e *Emitter) convert(o Operand, t string) Operand {
+	if o.varType == t {
+		return o
+	}
+	if e.settings.bigInts && t == "int" && isIntegral(o.varType) {
+		return e.bigInt(o)
+	}
+	if e.settings.bigInts && o.varType == "int" && isFloat(t) {
+		e.helper("pyBigFloat")
+		if t == "float32" {
+			return Operand{"float32(pyBigFloat(" + o.text + "))", t, 3}
+		}
+		return Operand{"pyBigFloat(" + o.text + ")", "float64", 3}
+	}
+	if !isFloat(t) || isFloat(o.varType) || strings.HasPrefix(o.varType, "untyped") {
+		return o
+	}
+	return Operand{t + "(" + o.text + ")", t, 3}
+}
+
+// A fixed width int or an int constant as a big int. Constants too big for
+// an int64 are given as a string
+func (e *Emitter) bigInt(o Operand) Operand {
+	e.require("math/big")
+	switch o.varType {
+	case "untyped int":
+		value, valid := new(big.Int).SetString(strings.ReplaceAll(o.text, "_", ""), 10)
+		if valid && !value.IsInt64() {
+			e.helper("pyBigInt")
+			return Operand{"pyBigInt(\"" + value.String() + "\")", "int", 3}
+		}
+		return Operand{"big.NewInt(" + o.text + ")", "int", 3}
+	case "int64":
+		return Operand{"big.NewInt(" + o.text + ")", "int", 3}
+	case "uint", "uint64", "uintptr":
+		return Operand{"new(big.Int).SetUint64(uint64(" + o.text + "))", "int", 3}
+	}
+	return Operand{"big.NewInt(int64(" + o.text + "))", "int", 3}
+}
+
+// Arithmetic on big ints, which math/big does with methods. Python's
+// rounding and powers need helpers
+func (e *Emitter) bigArithmetic(op string, left, right Operand) Operand {
+	e.require("math/big")
+	name := bigHelpers[op]
+	switch {
+	case bigMethods[op] != "":
+		name = "new(big.Int)." + bigMethods[op]
+	case e.settings.goSemantics && op == "//":
+		name = "new(big.Int).Quo"
+	case e.settings.goSemantics && op == "%":
+		name = "new(big.Int).Rem"
+	default:
+		e.helper(name)
+	}
+	return Operand{name + "(" + left.text + ", " + right.text + ")", "int", 3}
+}
+
+// Operators that can overflow, and the helper that checks each
+var checkedHelpers map[string]string = map[string]string{
+	"+":  "pyCheckedAdd",
+	"-":  "pyCheckedSub",
+	"*":  "pyCheckedMul",
+	"**": "pyCheckedPow",
+	"//": "pyCheckedFloorDiv",
+	"<<": "pyCheckedShift",
+}
+
+// Operators big ints have a method for, which is the same as Python's
+var bigMethods map[string]string = map[string]string{
+	"+":  "Add",
+	"-":  "Sub",
+	"*":  "Mul",
+	"&":  "And",
+	"|":  "Or",
+	"^":  "Xor",
+	"<<": "Lsh",
+	">>": "Rsh",
+}
+
+// An augmented assignment to a big int, written out in full. Shifts take
+// the count as a uint
+func (e *Emitter) bigAugmented(op string, target string, value string, t string) string {
+	right := Operand{value, t, 3}
+	if op == "<<" || op == ">>" {
+		if t == "int" {
+			right.text = "uint(" + value + ".Uint64())"
+		} else if t != "untyped int" {
+			right.text = "uint(" + value + ")"
+		}
+	}
+	return e.bigArithmetic(op, Operand{target, "int", 3}, right).text
+}
+
+// Comparisons with a big int, which math/big does with Cmp. A big int
+// compared with a float is compared as a float
+func (e *Emitter) emitComparison(ast Structure) (string, error) {
+	t := ast.children[1].varType
+	operands := []string{}
+	for i := 0; i < 3; i += 2 {
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return "", err
+		}
+		operands = append(operands, e.convert(Operand{strings.TrimSpace(temp), ast.children[i].varType, 0}, t).text)
+	}
+
+	if t == "int" {
+		return operands[0] + ".Cmp(" + operands[1] + ") " + ast.children[1].text + " 0", nil
+	}
+	return operands[0] + " " + ast.children[1].text + " " + operands[1], nil
+}
+
+var bigHelpers map[string]string = map[string]string{
+	"//": "pyBigFloorDiv",
+	"%":  "pyBigMod",
+	"**": "pyBigPow",
+}
+
+// An operand in brackets if Go would otherwise bind it to the wrong
+// operator
+func parenthesize(o Operand, level int, right bool) string {
+	if o.level < level || right && o.level == level {
+		return "(" + o.text + ")"
+	}
+	return o.text
+}
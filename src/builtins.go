@@ -0,0 +1,592 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Python's built in functions, with the fewest and most arguments each takes,
+// where -1 is any number. The numeric types are built in too, as conversions.
+// A program can still define its own function with any of these names
+var builtinFunctions map[string][2]int = map[string][2]int{
+	"abs":        {1, 1},
+	"all":        {1, 1},
+	"any":        {1, 1},
+	"bool":       {1, 1},
+	"chr":        {1, 1},
+	"divmod":     {2, 2},
+	"float":      {1, 1},
+	"input":      {0, 1},
+	"isinstance": {2, 2},
+	"len":        {1, 1},
+	"max":        {1, -1},
+	"min":        {1, -1},
+	"ord":        {1, 1},
+	"reversed":   {1, 1},
+	"round":      {1, 2},
+	"sorted":     {1, 1},
+	"str":        {1, 1},
+	"sum":        {1, 2},
+}
+
+func isBuiltinName(name string) bool {
+	_, registered := builtinFunctions[name]
+	return registered || isNumeric(name)
+}
+
+func isBuiltinFunction(s Structure, funcs []Function) bool {
+	if s.children[0].code != structureCode["FUNC_NAME"] {
+		return false
+	}
+	if _, defined := findFunction(s.children[0].text, funcs); defined {
+		return false
+	}
+	return isBuiltinName(s.children[0].text)
+}
+
+// Types that can be compared with < in Go
+func isOrdered(t string) bool {
+	return isIntegral(t) || isFloat(t) || t == "string"
+}
+
+// The type a call to a built in function gives, worked out from the types of
+// its arguments
+func (a *Analyzer) builtinType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	name := s.children[0].text
+	arity, registered := builtinFunctions[name]
+	if !registered {
+		arity = [2]int{1, 1}
+	}
+
+	count := 0
+	for i := 2; i+1 < len(s.children); i += 2 {
+		arg := s.children[i]
+		if arg.code != structureCode["KEYWORD_ARG"] {
+			count++
+			continue
+		}
+		if name != "sorted" || arg.children[0].text != "reverse" && arg.children[0].text != "key" {
+			return "", createError([]string{"builtins.go", "builtinType"}, name+" doesn't take the keyword argument \""+arg.children[0].text+"\"", s.line)
+		}
+		if arg.children[0].text == "key" {
+			continue // Checked once the type of the items is known
+		}
+		t, err := a.typeChild(arg, 2, vars, funcs)
+		if err != nil {
+			return "", err
+		}
+		if t != "bool" {
+			return "", createError([]string{"builtins.go", "builtinType"}, "Excpected bool got "+t+" for \"reverse\"", s.line)
+		}
+	}
+	if count < arity[0] || arity[1] >= 0 && count > arity[1] {
+		return "", createError([]string{"builtins.go", "builtinType"}, name+" takes "+argumentCount(arity), s.line)
+	}
+
+	switch {
+	case name == "divmod":
+		return a.divmodType(s, vars, funcs)
+	case name == "float":
+		return a.conversionType(s, "float64", vars, funcs)
+	case isNumeric(name):
+		return a.conversionType(s, name, vars, funcs)
+	case name == "isinstance":
+		return a.isinstanceType(s, vars, funcs)
+	}
+
+	types := []string{}
+	for i := 2; i+1 < len(s.children); i += 2 {
+		if s.children[i].code == structureCode["KEYWORD_ARG"] {
+			continue
+		}
+		if name == "str" || name == "bool" {
+			settle(s, i, "any")
+		}
+		t, err := a.typeChild(s, i, vars, funcs)
+		if err != nil {
+			return "", err
+		}
+		if isTuple(t) {
+			return "", createError([]string{"builtins.go", "builtinType"}, "Multiple values can't be used as an argument", s.line)
+		}
+		types = append(types, t)
+	}
+
+	t := ""
+	if len(types) > 0 {
+		t = types[0]
+	}
+	base, items := typeArguments(t)
+	switch name {
+	case "len":
+		if t == "string" || base == "list" || base == "dict" {
+			return "int", nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "A value of type "+t+" has no len()", s.line)
+	case "abs":
+		if isIntegral(t) || isFloat(t) {
+			return defaultType(t), nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "abs takes a number, got "+t, s.line)
+	case "min", "max":
+		return a.extremeType(s, types)
+	case "sum":
+		if base != "list" || !isIntegral(items[0]) && !isFloat(items[0]) {
+			return "", createError([]string{"builtins.go", "builtinType"}, "sum takes a list of numbers, got "+t, s.line)
+		}
+		if len(types) == 1 {
+			return items[0], nil
+		}
+		sum, err := arithmeticType("+", types[1], items[0], a.settings, s.line)
+		return defaultType(sum), err
+	case "sorted":
+		for i := 2; i+1 < len(s.children); i += 2 {
+			arg := s.children[i]
+			if arg.code != structureCode["KEYWORD_ARG"] || arg.children[0].text != "key" {
+				continue
+			}
+			item := "string"
+			if base == "list" || base == "dict" {
+				item = items[0]
+			} else if t != "string" {
+				break
+			}
+			_, err := a.sortKeyType(arg, item, vars, funcs)
+			if err != nil {
+				return "", err
+			}
+			return "list[" + item + "]", nil
+		}
+		if t == "string" {
+			return "list[string]", nil
+		}
+		if (base == "list" || base == "dict") && isOrdered(items[0]) {
+			return "list[" + items[0] + "]", nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "sorted takes a string, or a list or dict of numbers or strings, got "+t, s.line)
+	case "reversed":
+		if base == "list" {
+			return t, nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "reversed takes a list, got "+t, s.line)
+	case "any", "all":
+		if base == "list" && a.settings.bigInts && items[0] == "int" {
+			return "", createError([]string{"builtins.go", "builtinType"}, name+" can't take a list of int with -big-ints", s.line)
+		}
+		if base == "list" && (isOrdered(items[0]) || items[0] == "bool") {
+			return "bool", nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, name+" takes a list of bools, numbers or strings, got "+t, s.line)
+	case "str":
+		return "string", nil
+	case "bool":
+		return "bool", nil
+	case "ord":
+		if t == "string" {
+			return "int", nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "ord takes a string, got "+t, s.line)
+	case "chr":
+		if isIntegral(t) {
+			return "string", nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "chr takes an int, got "+t, s.line)
+	case "input":
+		if t == "" || t == "string" {
+			return "string", nil
+		}
+		return "", createError([]string{"builtins.go", "builtinType"}, "input takes a string, got "+t, s.line)
+	}
+
+	// round gives an int, unless it is given how many digits to keep
+	if len(types) == 2 && (!isFloat(t) && !isIntegral(t) || !isIntegral(types[1])) {
+		return "", createError([]string{"builtins.go", "builtinType"}, "round with ndigits takes a number and an int, got "+t+" and "+types[1], s.line)
+	}
+	if len(types) == 2 || isIntegral(t) {
+		return defaultType(t), nil
+	}
+	if isFloat(t) {
+		return "int", nil
+	}
+	return "", createError([]string{"builtins.go", "builtinType"}, "round takes a number, got "+t, s.line)
+}
+
+func argumentCount(arity [2]int) string {
+	switch {
+	case arity[0] == arity[1] && arity[0] == 1:
+		return "1 argument"
+	case arity[0] == arity[1]:
+		return strconv.Itoa(arity[0]) + " arguments"
+	case arity[1] < 0:
+		return "at least " + strconv.Itoa(arity[0]) + " argument"
+	}
+	return strconv.Itoa(arity[0]) + " to " + strconv.Itoa(arity[1]) + " arguments"
+}
+
+// min and max, of one list or string, or of several values. Ints mixed with
+// floats are promoted, as they are in arithmetic
+func (a *Analyzer) extremeType(s Structure, types []string) (string, error) {
+	name := s.children[0].text
+	if len(types) == 1 {
+		base, items := typeArguments(types[0])
+		if types[0] == "string" {
+			return "string", nil
+		}
+		if base == "list" && isOrdered(items[0]) {
+			return items[0], nil
+		}
+		return "", createError([]string{"builtins.go", "extremeType"}, name+" takes a string, a list of numbers or strings, or several numbers or strings, got "+types[0], s.line)
+	}
+
+	t := types[0]
+	for i := 1; i < len(types); i++ {
+		if (isIntegral(t) || isFloat(t)) && (isIntegral(types[i]) || isFloat(types[i])) {
+			var err error
+			t, err = arithmeticType("+", t, types[i], a.settings, s.line)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		var valid bool
+		t, valid = combineTypes(t, types[i])
+		if !valid || !isOrdered(t) {
+			return "", createError([]string{"builtins.go", "extremeType"}, name+" can't compare "+types[i-1]+" and "+types[i], s.line)
+		}
+	}
+
+	t = defaultType(t)
+	for i := 0; i < len(types); i++ {
+		if types[i] == "untyped int" && fixedWidth(t, a.settings) {
+			err := checkConstant(s.children[2+i*2], arrange(s.children[2+i*2]), t)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return t, nil
+}
+
+// isinstance, which takes the name of a class rather than a value
+func (a *Analyzer) isinstanceType(s Structure, vars []Variable, funcs []Function) (string, error) {
+	t, err := a.typeChild(s, 2, vars, funcs)
+	if err != nil {
+		return "", err
+	}
+
+	class := s.children[4]
+	if class.code != structureCode["EXPRESSION"] || len(class.children) != 1 || class.children[0].code != structureCode["IDENTIFIER"] {
+		return "", createError([]string{"builtins.go", "isinstanceType"}, "The second argument of isinstance must be a class", s.line)
+	}
+	name := class.children[0].text
+	_, exception := exceptionParents[name]
+	if pythonClasses[name] == "" && !isNumeric(name) && name != "string" && a.classes[name].name == "" && !exception {
+		return "", createError([]string{"builtins.go", "isinstanceType"}, "\""+name+"\" isn't a class isinstance knows", s.line)
+	}
+	if t == "any" && (name == "list" || name == "dict") {
+		return "", createError([]string{"builtins.go", "isinstanceType"}, "A value of type any can't be checked against "+name+", as Go needs the type of its items", s.line)
+	}
+	return "bool", nil
+}
+
+// Python's own classes, and the type each is here
+var pythonClasses map[string]string = map[string]string{
+	"bool":  "bool",
+	"dict":  "dict",
+	"float": "float64",
+	"int":   "int",
+	"list":  "list",
+	"str":   "string",
+}
+
+// Whether a value of type t is an instance of a class, worked out from its
+// declared type. GoType's ints and floats subclass Python's, and bool is an
+// int in Python too
+func instanceOf(t, class string) bool {
+	base, _ := typeArguments(t)
+	switch class {
+	case "int":
+		return isIntegral(t) || t == "bool"
+	case "float":
+		return isFloat(t)
+	case "str":
+		return t == "string"
+	case "list", "dict":
+		return base == class
+	}
+	if _, exception := exceptionParents[class]; exception {
+		return assignable(class, t)
+	}
+	return defaultType(t) == class
+}
+
+// A call to a built in function, as Go or a runtime helper
+func (e *Emitter) emitBuiltin(ast Structure) (string, error) {
+	name := ast.children[0].text
+	switch {
+	case name == "divmod":
+		return e.emitDivmod(ast)
+	case name == "float" || isNumeric(name):
+		return e.emitConversion(ast)
+	case name == "isinstance":
+		return e.emitIsinstance(ast)
+	}
+
+	args := []Operand{}
+	reverse := "false"
+	var key Operand
+	for i := 2; i+1 < len(ast.children); i += 2 {
+		arg := ast.children[i]
+		if arg.code == structureCode["KEYWORD_ARG"] {
+			arg = arg.children[2]
+		}
+		temp, err := e.emit(arg)
+		if err != nil {
+			return "", err
+		}
+		if ast.children[i].code == structureCode["KEYWORD_ARG"] && ast.children[i].children[0].text == "key" {
+			key = Operand{strings.TrimSpace(temp), arg.varType, 3}
+			continue
+		}
+		if ast.children[i].code == structureCode["KEYWORD_ARG"] {
+			reverse = strings.TrimSpace(temp)
+			continue
+		}
+		args = append(args, Operand{strings.TrimSpace(temp), arg.varType, 3})
+	}
+
+	t := ast.varType
+	big := e.settings.bigInts && t == "int"
+	var x Operand
+	var items []string
+	if len(args) > 0 {
+		x = args[0]
+		_, items = typeArguments(x.varType)
+	}
+
+	switch name {
+	case "len":
+		if x.varType == "string" {
+			e.require("unicode/utf8")
+			return e.pythonInt("utf8.RuneCountInString(" + x.text + ")"), nil
+		}
+		if strings.HasPrefix(x.varType, "dict") {
+			return e.pythonInt("len(" + x.text + ".keys)"), nil
+		}
+		return e.pythonInt("len(" + x.text + ")"), nil
+	case "abs":
+		return e.emitAbs(x), nil
+	case "min", "max":
+		return e.emitExtreme(name, args, t), nil
+	case "sum":
+		helper := "pySum"
+		if e.settings.bigInts && items[0] == "int" {
+			helper = "pyBigSum"
+		}
+		e.helper(helper)
+		sum := Operand{helper + "(" + x.text + ")", items[0], 3}
+		if len(args) == 2 {
+			return e.arithmetic("+", args[1], sum, t, ast.line).text, nil
+		}
+		return sum.text, nil
+	case "sorted":
+		if x.varType == "string" {
+			e.require("strings")
+			x.text = "strings.Split(" + x.text + ", \"\")"
+		} else if strings.HasPrefix(x.varType, "dict") {
+			x.text += ".keys"
+		}
+		if _, result := callableSignature(key.varType); key.text != "" && e.settings.bigInts && result == "int" {
+			e.helper("pyBigSortedBy")
+			return "pyBigSortedBy(" + x.text + ", " + key.text + ", " + reverse + ")", nil
+		} else if key.text != "" {
+			e.helper("pySortedBy")
+			return "pySortedBy(" + x.text + ", " + key.text + ", " + reverse + ")", nil
+		}
+		if e.settings.bigInts && t == "list[int]" {
+			e.helper("pyBigSorted")
+			return "pyBigSorted(" + x.text + ", " + reverse + ")", nil
+		}
+		e.helper("pySorted")
+		return "pySorted(" + x.text + ", " + reverse + ")", nil
+	case "reversed":
+		e.helper("pyReversed")
+		return "pyReversed(" + x.text + ")", nil
+	case "any":
+		e.helper("pyAny")
+		return "pyAny(" + x.text + ")", nil
+	case "all":
+		e.helper("pyAll")
+		return "pyAll(" + x.text + ")", nil
+	case "str":
+		if x.varType == "string" {
+			return x.text, nil
+		}
+		e.helper("pyStr")
+		return "pyStr(" + x.text + ")", nil
+	case "bool":
+		return e.truth(x), nil
+	case "ord":
+		if r, constant := constantCharacter(ast.children[2]); constant {
+			return e.pythonInt("int(" + strconv.QuoteRune(r) + ")"), nil
+		}
+		if e.settings.bigInts {
+			e.helper("pyBigOrd")
+			return "pyBigOrd(" + x.text + ")", nil
+		}
+		e.helper("pyOrd")
+		return "pyOrd(" + x.text + ")", nil
+	case "chr":
+		if e.settings.bigInts && x.varType == "int" {
+			return "string(rune(" + x.text + ".Int64()))", nil
+		}
+		return "string(rune(" + x.text + "))", nil
+	case "input":
+		prompt := "\"\""
+		if len(args) == 1 {
+			prompt = x.text
+		}
+		e.helper("pyInput")
+		return "pyInput(" + prompt + ")", nil
+	}
+
+	// round, which rounds halves to even like Python's
+	ndigits := ""
+	if len(args) == 2 && e.settings.bigInts && args[1].varType == "int" {
+		ndigits = "int(" + args[1].text + ".Int64())"
+	} else if len(args) == 2 {
+		ndigits = "int(" + args[1].text + ")"
+	}
+	if len(args) == 2 && big && isIntegral(x.varType) {
+		e.helper("pyBigRoundInt")
+		return "pyBigRoundInt(" + e.convert(x, t).text + ", " + ndigits + ")", nil
+	}
+	if len(args) == 2 && isIntegral(x.varType) {
+		e.helper("pyRoundInt")
+		return "pyRoundInt(" + e.convert(x, t).text + ", " + ndigits + ")", nil
+	}
+	if isIntegral(x.varType) {
+		return x.text, nil
+	}
+	e.require("math")
+	if x.varType == "float32" {
+		x.text = "float64(" + x.text + ")"
+	}
+	if len(args) == 2 {
+		e.helper("pyRound")
+		if t == "float32" {
+			return "float32(pyRound(" + x.text + ", " + ndigits + "))", nil
+		}
+		return "pyRound(" + x.text + ", " + ndigits + ")", nil
+	}
+	if big {
+		e.helper("pyBigTrunc")
+		return "pyBigTrunc(math.RoundToEven(" + x.text + "))", nil
+	}
+	return "int(math.RoundToEven(" + x.text + "))", nil
+}
+
+// A Go int as a Python int, which is a big int with -big-ints
+func (e *Emitter) pythonInt(text string) string {
+	if e.settings.bigInts {
+		e.require("math/big")
+		return "big.NewInt(int64(" + text + "))"
+	}
+	return text
+}
+
+func (e *Emitter) emitAbs(x Operand) string {
+	switch {
+	case e.settings.bigInts && x.varType == "int":
+		return "new(big.Int).Abs(" + x.text + ")"
+	case x.varType == "float32":
+		e.require("math")
+		return "float32(math.Abs(float64(" + x.text + ")))"
+	case isFloat(x.varType):
+		e.require("math")
+		return "math.Abs(" + x.text + ")"
+	case isInteger(x.varType) && strings.HasPrefix(x.varType, "u") || x.varType == "byte":
+		return x.text
+	}
+	e.helper("pyAbs")
+	if x.varType == "untyped int" {
+		return "pyAbs(int(" + x.text + "))"
+	}
+	return "pyAbs(" + x.text + ")"
+}
+
+// min and max, of several values, or of a list or string with the Of
+// helpers, which give a ValueError when it is empty
+func (e *Emitter) emitExtreme(name string, args []Operand, t string) string {
+	helper := "py" + strings.ToUpper(name[:1]) + name[1:]
+	if e.settings.bigInts && t == "int" {
+		helper = "pyBig" + helper[2:]
+	}
+
+	if len(args) == 1 && args[0].varType == "string" {
+		e.helper(helper + "Of")
+		e.require("strings")
+		return helper + "Of(strings.Split(" + args[0].text + ", \"\"))"
+	}
+	if len(args) == 1 {
+		e.helper(helper + "Of")
+		return helper + "Of(" + args[0].text + ")"
+	}
+
+	e.helper(helper)
+	values := []string{}
+	for i := 0; i < len(args); i++ {
+		values = append(values, e.convert(args[i], t).text)
+	}
+	if e.settings.bigInts && t == "int" {
+		return helper + "(" + strings.Join(values, ", ") + ")"
+	}
+	return helper + "[" + e.goType(t) + "](" + strings.Join(values, ", ") + ")"
+}
+
+// Python's truthiness, where zero and empty values are false
+func (e *Emitter) truth(x Operand) string {
+	base, _ := typeArguments(x.varType)
+	switch {
+	case x.varType == "bool":
+		return x.text
+	case e.settings.bigInts && x.varType == "int":
+		return "(" + x.text + ".Sign() != 0)"
+	case isIntegral(x.varType) || isFloat(x.varType):
+		return "(" + x.text + " != 0)"
+	case x.varType == "string":
+		return "(" + x.text + " != \"\")"
+	case base == "list":
+		return "(len(" + x.text + ") > 0)"
+	case base == "dict":
+		return "(len(" + x.text + ".keys) > 0)"
+	case x.varType == "None":
+		return "false"
+	case x.varType == "any":
+		e.helper("pyBool")
+		return "pyBool(" + x.text + ")"
+	}
+	return "(" + x.text + " != nil)"
+}
+
+// isinstance is known from the declared type, except for values of type any,
+// which are checked for the Go type they hold
+func (e *Emitter) emitIsinstance(ast Structure) (string, error) {
+	temp, err := e.emit(ast.children[2])
+	if err != nil {
+		return "", err
+	}
+	x := Operand{strings.TrimSpace(temp), ast.children[2].varType, 3}
+
+	class := ast.children[4].children[0].text
+	if x.varType != "any" {
+		return strconv.FormatBool(instanceOf(x.varType, class)), nil
+	}
+
+	t := class
+	if pythonClasses[class] != "" {
+		t = pythonClasses[class]
+	}
+	e.helper("pyIsInstance")
+	return "pyIsInstance[" + e.goType(t) + "](" + x.text + ")", nil
+}
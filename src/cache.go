@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pogo build keeps what it made of each module in the project's .pogo-cache
+// directory. A module whose source, compiler and settings are the same isn't
+// parsed again, unless a module it imports has different signatures
+type CacheEntry struct {
+	Key        string
+	Locals     []string
+	Defines    []string
+	Variables  map[string]string // Its module level variables, and their types in Go
+	Signatures []CachedStructure // Its functions and classes, with empty function bodies
+	Raising    []string          // Its functions that may raise
+	HasCode    bool              // Whether it has top level code to run
+	Helpers    []string
+	Source     string
+}
+
+// A Structure as it is written to the cache
+type CachedStructure struct {
+	Code     int
+	Text     string
+	Line     int
+	Children []CachedStructure
+}
+
+func cacheStructure(s Structure) CachedStructure {
+	c := CachedStructure{s.code, s.text, s.line, []CachedStructure{}}
+	for i := 0; i < len(s.children); i++ {
+		c.Children = append(c.Children, cacheStructure(s.children[i]))
+	}
+	return c
+}
+
+func (c CachedStructure) structure() Structure {
+	s := Structure{c.Code, c.Text, c.Line, []Structure{}, ""}
+	for i := 0; i < len(c.Children); i++ {
+		s.children = append(s.children, c.Children[i].structure())
+	}
+	return s
+}
+
+// A function or class without the bodies of its functions, which is all the
+// analyzer needs to know what it declares
+func signatureOf(s Structure) CachedStructure {
+	c := cacheStructure(s)
+	body := &c.Children[len(c.Children)-1]
+	if s.code == structureCode["ST_FUNCTION"] {
+		body.Children = []CachedStructure{}
+		return c
+	}
+	for i := 0; i < len(body.Children); i++ {
+		if body.Children[i].Code == structureCode["ST_FUNCTION"] {
+			method := &body.Children[i]
+			method.Children[len(method.Children)-1].Children = []CachedStructure{}
+		}
+	}
+	return c
+}
+
+// Whether two builds of a module look the same to the modules that import it
+func sameSignatures(a, b CacheEntry) bool {
+	left, _ := json.Marshal([]any{a.Defines, a.Variables, a.Signatures, a.Raising, a.HasCode})
+	right, _ := json.Marshal([]any{b.Defines, b.Variables, b.Signatures, b.Raising, b.HasCode})
+	return string(left) == string(right)
+}
+
+func cacheKey(input []byte, salt string) string {
+	sum := sha256.Sum256(append([]byte(salt), input...))
+	return hex.EncodeToString(sum[:])
+}
+
+// What besides the source changes the Go a module compiles to: the Pogo
+// binary, and the settings
+func cacheSalt(settings Settings) string {
+	compiler := "unknown"
+	if path, err := os.Executable(); err == nil {
+		if binary, err := os.ReadFile(path); err == nil {
+			sum := sha256.Sum256(binary)
+			compiler = hex.EncodeToString(sum[:])
+		}
+	}
+	return compiler + fmt.Sprintf("%+v", settings)
+}
+
+// The cache entries of the last build, by module. A cache that can't be read
+// is treated as empty
+func readCache(dir string) map[string]CacheEntry {
+	cache := map[string]CacheEntry{}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for i := 0; i < len(files); i++ {
+		source, err := os.ReadFile(files[i])
+		if err != nil {
+			continue
+		}
+		entry := CacheEntry{}
+		if json.Unmarshal(source, &entry) == nil {
+			cache[strings.TrimSuffix(filepath.Base(files[i]), ".json")] = entry
+		}
+	}
+	return cache
+}
+
+// Writes the cache entry of each module, removing the entries of modules
+// that are gone
+func writeCache(dir string, modules []Module, entries []CacheEntry) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return createError([]string{"cache.go", "writeCache"}, err.Error(), 0)
+	}
+
+	current := map[string]bool{}
+	for i := 0; i < len(modules); i++ {
+		current[modules[i].name+".json"] = true
+	}
+	old, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for i := 0; i < len(old); i++ {
+		if !current[filepath.Base(old[i])] {
+			os.Remove(old[i])
+		}
+	}
+
+	for i := 0; i < len(modules); i++ {
+		source, _ := json.Marshal(entries[i])
+		err := os.WriteFile(filepath.Join(dir, modules[i].name+".json"), source, 0644)
+		if err != nil {
+			return createError([]string{"cache.go", "writeCache"}, err.Error(), 0)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Functions are values of type Callable[[params], result], which is
+// func(params) result in Go. Named functions can be used as values, and
+// lambdas take the types of their parameters from the Callable they are
+// used as
+
+func isCallable(t string) bool {
+	base, _ := typeArguments(t)
+	return base == "Callable"
+}
+
+// The parameter types and result of a Callable
+func callableSignature(t string) ([]string, string) {
+	_, args := typeArguments(t)
+	if len(args) != 2 {
+		return []string{}, ""
+	}
+	_, params := typeArguments(args[0])
+	if len(params) == 1 && params[0] == "" {
+		params = []string{}
+	}
+	return params, args[1]
+}
+
+func callableType(params []string, result string) string {
+	return "Callable[[" + strings.Join(params, ", ") + "], " + result + "]"
+}
+
+// A variable holding a function, which can be called like one
+func callableFunction(name string, t string) Function {
+	params, result := callableSignature(t)
+	fn := Function{name: name, params: params, varType: result, positional: len(params)}
+	for i := 0; i < len(params); i++ {
+		fn.names = append(fn.names, "")
+		fn.defaults = append(fn.defaults, Structure{})
+	}
+	return fn
+}
+
+// The type of a named function used as a value. Functions that may raise
+// return an error in Go, so they can't be, and neither can classes or the
+// functions the emitter provides
+func (a *Analyzer) functionValueType(s Structure, fn Function) (string, error) {
+	_, exception := exceptionParents[fn.name]
+	if fn.builtin || fn.name == "print" || exception || a.classes[fn.name].name != "" {
+		return "", createError([]string{"callables.go", "functionValueType"}, "\""+fn.name+"\" can't be used as a value", s.line)
+	}
+	if a.raising[fn.name] {
+		return "", createError([]string{"callables.go", "functionValueType"}, "\""+fn.name+"\" may raise, so it can't be used as a value", s.line)
+	}
+	if fn.variadic {
+		return "", createError([]string{"callables.go", "functionValueType"}, "\""+fn.name+"\" has a variadic parameter, so it can't be used as a value", s.line)
+	}
+	return callableType(fn.params, fn.varType), nil
+}
+
+// The type of a lambda with the given parameter types. Its result is checked
+// against result, or is whatever the body gives when result is ""
+func (a *Analyzer) lambdaType(s Structure, params []string, result string, vars []Variable, funcs []Function) (string, error) {
+	inner := append([]Variable{}, vars...)
+	count := 0
+	for i := 1; s.children[i].code == structureCode["IDENTIFIER"]; i += 2 {
+		if count < len(params) {
+			inner = append(inner, Variable{s.children[i].text, params[count]})
+		}
+		count++
+	}
+	if count != len(params) {
+		return "", createError([]string{"callables.go", "lambdaType"}, "Excpected a lambda with "+strconv.Itoa(len(params))+" parameters got "+strconv.Itoa(count), s.line)
+	}
+
+	body := len(s.children) - 1
+	if result != "" {
+		settle(s, body, result)
+	}
+	err := a.analyze(s.children[body], inner, funcs)
+	if err != nil {
+		return "", err
+	}
+	t, err := a.typeChild(s, body, inner, funcs)
+	if err != nil {
+		return "", err
+	}
+	if mayRaise(s.children[body], a.raising) {
+		return "", createError([]string{"callables.go", "lambdaType"}, "This lambda may raise, which a lambda can't, as a Callable has no error to return. Use a def instead, and catch the exception in it", s.line)
+	}
+
+	if result == "" {
+		return callableType(params, defaultType(t)), nil
+	}
+	if !assignable(result, t) {
+		return "", createError([]string{"callables.go", "lambdaType"}, "Excpected "+result+" got "+t+" in lambda", s.line)
+	}
+	return callableType(params, result), nil
+}
+
+// The type of the key given to sorted, which takes an item and gives what
+// the items are compared by. A lambda's result is worked out from its body
+func (a *Analyzer) sortKeyType(s Structure, item string, vars []Variable, funcs []Function) (string, error) {
+	key := s.children[2]
+	if len(key.children) == 1 && key.children[0].code == structureCode["IDENTIFIER"] {
+		name := key.children[0].text
+		_, variable := findVariable(name, vars)
+		_, function := findFunction(name, funcs)
+		if !variable && !function && isBuiltinName(name) {
+			key.children[0] = builtinLambda(name, key.line)
+		}
+	}
+	if len(key.children) == 1 && key.children[0].code == structureCode["LAMBDA"] {
+		t, err := a.lambdaType(key.children[0], []string{item}, "", vars, funcs)
+		if err != nil {
+			return "", err
+		}
+		key.children[0].varType = t
+	}
+
+	t, err := a.typeChild(s, 2, vars, funcs)
+	if err != nil {
+		return "", err
+	}
+	params, result := callableSignature(t)
+	if !isCallable(t) || len(params) != 1 || params[0] != item {
+		return "", createError([]string{"callables.go", "sortKeyType"}, "Excpected "+callableType([]string{item}, "T")+" got "+t+" for \"key\"", s.line)
+	}
+	if !isOrdered(result) && !(a.settings.bigInts && result == "int") {
+		return "", createError([]string{"callables.go", "sortKeyType"}, "The key of sorted has to give a number or a string, got "+result, s.line)
+	}
+	return t, nil
+}
+
+// A built in function given as a key, such as key=len, as a lambda calling
+// it, since what a built in function takes depends on how it is called
+func builtinLambda(name string, line int) Structure {
+	param := createStructure("IDENTIFIER", "pyKey", line)
+	arg := createStructure("EXPRESSION", "EXPRESSION", line)
+	arg.children = append(arg.children, param)
+
+	call := createStructure("ST_CALL", "ST_CALL", line)
+	call.children = append(call.children,
+		createStructure("FUNC_NAME", name, line),
+		createStructure("L_PAREN", "(", line),
+		arg,
+		createStructure("R_PAREN", ")", line),
+	)
+	body := createStructure("EXPRESSION", "EXPRESSION", line)
+	body.children = append(body.children, call)
+
+	lambda := createStructure("LAMBDA", "LAMBDA", line)
+	lambda.children = append(lambda.children,
+		createStructure("K_LAMBDA", "lambda", line),
+		param,
+		createStructure("COLON", ":", line),
+		body,
+	)
+	return lambda
+}
+
+// A Go function type, such as func(int, int) int
+func (e *Emitter) funcType(t string) string {
+	params, result := callableSignature(t)
+	for i := 0; i < len(params); i++ {
+		params[i] = e.goType(params[i])
+	}
+	output := "func(" + strings.Join(params, ", ") + ")"
+	if result != "None" {
+		output += " " + e.goType(result)
+	}
+	return output
+}
+
+// A lambda, as a function literal. Code the body needs first goes inside it
+func (e *Emitter) emitLambda(ast Structure) (string, error) {
+	types, result := callableSignature(ast.varType)
+	params := []string{}
+	for i := 1; ast.children[i].code == structureCode["IDENTIFIER"]; i += 2 {
+		params = append(params, ast.children[i].text+" "+e.goType(types[len(params)]))
+	}
+
+	context := FunctionContext{[]string{}, false, false}
+	if result != "None" {
+		context.results = []string{e.goType(result)}
+	}
+	outer, tries, excepts, loops := e.pre, e.tries, e.excepts, e.loops
+	e.pre, e.tries, e.excepts, e.loops = []string{}, []int{}, []int{}, []string{}
+	e.functions = append(e.functions, context)
+	body, err := e.emit(ast.children[len(ast.children)-1])
+	e.functions = e.functions[:len(e.functions)-1]
+	pre := strings.Join(e.pre, "")
+	e.pre, e.tries, e.excepts, e.loops = outer, tries, excepts, loops
+	if err != nil {
+		return "", err
+	}
+
+	output := "func(" + strings.Join(params, ", ") + ")"
+	if result == "None" {
+		return output + " {\n" + pre + body + "\n}", nil
+	}
+	return output + " " + context.results[0] + " {\n" + pre + "return " + body + "\n}", nil
+}
@@ -0,0 +1,119 @@
+package main
+
+// Functions defined in another function are Go closures, which see the
+// variables around them. As in Python, assigning to one of those needs
+// nonlocal, and assigning to a variable of the module needs global. Module
+// level variables named by global are Go package variables
+
+// Checks a variable can be assigned to in the function being analyzed.
+// Python would make a new variable, where Go would change the one outside
+func (a *Analyzer) checkAssignable(name string, vars []Variable, line int) error {
+	if len(a.locals) == 0 {
+		return nil
+	}
+	for i := len(vars) - 1; i >= a.locals[len(a.locals)-1]; i-- {
+		if vars[i].name == name {
+			return nil
+		}
+	}
+	if _, exists := findVariable(name, vars); exists {
+		return createError([]string{"closures.go", "checkAssignable"}, "\""+name+"\" belongs to an enclosing function, so it needs nonlocal "+name+" to be assigned to", line)
+	}
+	return nil
+}
+
+// The variables a nonlocal or global statement lets the function assign to
+func (a *Analyzer) outerVariables(s Structure, vars []Variable) ([]Variable, error) {
+	start := 0
+	if len(a.locals) > 0 {
+		start = a.locals[len(a.locals)-1]
+	}
+
+	found := []Variable{}
+	for i := 1; i < len(s.children); i += 2 {
+		name := s.children[i].text
+		for j := start; j < len(vars); j++ {
+			if vars[j].name == name {
+				return nil, createError([]string{"closures.go", "outerVariables"}, "\""+name+"\" is used before its "+s.children[0].text+" declaration", s.line)
+			}
+		}
+
+		if s.code == structureCode["ST_GLOBAL"] {
+			t, exists := a.globals[name]
+			if !exists {
+				return nil, createError([]string{"closures.go", "outerVariables"}, "There is no module level variable \""+name+"\" declared with a type", s.line)
+			}
+			found = append(found, Variable{name, t})
+			continue
+		}
+
+		variable, exists := findVariable(name, vars[:start])
+		if len(a.locals) < 2 || !exists {
+			return nil, createError([]string{"closures.go", "outerVariables"}, "No binding for nonlocal \""+name+"\" found", s.line)
+		}
+		found = append(found, variable)
+	}
+	return found, nil
+}
+
+// Finds the module level variables of a module, which its functions can name
+// with global. They are declared in Go's package scope, as packageVariables
+// gives them, and only assigned where Python declares them
+func (a *Analyzer) declareGlobals(module Module) error {
+	if a.globals == nil {
+		a.globals = map[string]string{}
+	}
+
+	for i := 0; i < len(module.body); i++ {
+		s := module.body[i]
+		if s.code != structureCode["ST_DECLARATION"] {
+			continue
+		}
+		if _, exists := a.globals[s.children[0].text]; exists {
+			return createError([]string{"closures.go", "declareGlobals"}, "The global \""+s.children[0].text+"\" is declared more than once", s.line)
+		}
+		a.globals[s.children[0].text] = s.children[2].text
+		module.body[i].varType = "global"
+	}
+	return nil
+}
+
+// Whether a function refers to a name, either calling it, or using it as a
+// value
+func refersTo(s Structure, name string) bool {
+	if (s.code == structureCode["IDENTIFIER"] || s.code == structureCode["FUNC_NAME"]) && s.text == name {
+		return true
+	}
+	for i := 0; i < len(s.children); i++ {
+		if refersTo(s.children[i], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// A function defined in another, as a closure. One that refers to itself is
+// declared first, so it can
+func (e *Emitter) emitClosure(ast Structure) (string, error) {
+	name := ast.children[1].text
+	signature, body, err := e.functionParts(ast, "")
+	if err != nil {
+		return "", err
+	}
+	if refersTo(ast.children[len(ast.children)-1], name) {
+		return "\nvar " + name + " func" + signature + "\n" + name + " = func" + signature + " " + body + "\n_ = " + name + "\n", nil
+	}
+	return "\n" + name + " := func" + signature + " " + body + "\n_ = " + name + "\n", nil
+}
+
+// The package variables for the globals declared in top level code
+func (e *Emitter) globalVariables(body Structure) string {
+	output := ""
+	for i := 0; i < len(body.children); i++ {
+		s := body.children[i]
+		if s.code == structureCode["ST_DECLARATION"] && s.varType == "global" {
+			output += "\nvar " + s.children[0].text + " " + e.goType(s.children[2].text) + "\n"
+		}
+	}
+	return output
+}
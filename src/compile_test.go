@@ -0,0 +1,764 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Compiles a program, written with \n line endings, then builds and runs the
+// Go it was compiled to, giving what it printed
+func runProgram(t *testing.T, source string, settings Settings) string {
+	t.Helper()
+	return runProgramIn(t, t.TempDir(), source, settings)
+}
+
+// Compiles and runs a program in dir, next to the modules already written there
+func runProgramIn(t *testing.T, dir string, source string, settings Settings) string {
+	t.Helper()
+	output, err := compileSource(dir, source, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "prog.go"), []byte(output), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := exec.Command("go", "run", "prog.go")
+	run.Dir = dir
+	printed, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s\n%s", err, printed, output)
+	}
+	return string(printed)
+}
+
+func compileSource(dir string, source string, settings Settings) (string, error) {
+	err := os.WriteFile(filepath.Join(dir, "prog.py"), []byte(strings.ReplaceAll(source, "\n", "\r\n")), 0644)
+	if err != nil {
+		return "", err
+	}
+	modules, err := loadProgram(filepath.Join(dir, "prog"))
+	if err != nil {
+		return "", err
+	}
+	return compile(modules, settings)
+}
+
+func expectOutput(t *testing.T, source string, settings Settings, want string) {
+	t.Helper()
+	if got := runProgram(t, source, settings); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGoErrorsCanBeCaught(t *testing.T) {
+	source := `from GoType.go import strconv
+
+def parse(s: string) -> int:
+    return strconv.Atoi(s)
+
+try:
+    n: int = strconv.Atoi("x1")
+    print(n)
+except Exception as e:
+    print("caught", e)
+
+try:
+    print(parse("12") + parse("oops"))
+except Exception:
+    print("caught in caller")
+print(parse("41") + 1)
+`
+	want := "caught strconv.Atoi: parsing \"x1\": invalid syntax\ncaught in caller\n42\n"
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestParsingRaisesValueError(t *testing.T) {
+	source := `def to_int(s: string) -> int:
+    return int(s)
+
+def total(items: list[string]) -> int:
+    sum: int = 0
+    for item in items:
+        sum += to_int(item)
+    return sum
+
+try:
+    n: float64 = float("1.5x")
+    print(n)
+except ValueError as e:
+    print("caught", e)
+
+try:
+    print(total(["1", "2", "three"]))
+except ValueError as e:
+    print("caught", e)
+print(total(["1", "2", "3"]))
+`
+	want := "caught could not convert string to float: '1.5x'\ncaught invalid literal for int() with base 10: 'three'\n6\n"
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestBuiltinsRaiseExceptions(t *testing.T) {
+	source := `from GoType import *
+
+def biggest(xs: list[int]) -> int:
+    return max(xs)
+
+empty: list[int] = []
+try:
+    print(biggest(empty))
+except ValueError as e:
+    print("caught", e)
+try:
+    print(min(""))
+except ValueError as e:
+    print("caught", e)
+try:
+    print(ord("ab"))
+except TypeError as e:
+    print("caught", e)
+try:
+    line: string = input()
+    print(line)
+except EOFError as e:
+    print("caught", e)
+print(biggest([3, 9, 4]), min(2, 1), ord("a"))
+`
+	want := "caught max() arg is an empty sequence\ncaught min() arg is an empty sequence\ncaught ord() expected a character, but string of length 2 found\ncaught EOF when reading a line\n9 1 97\n"
+	if got := runPython(t, source); got != want {
+		t.Fatalf("python printed\n%s", got)
+	}
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestRoundOfInts(t *testing.T) {
+	source := `x: int = 7
+n: int = -1
+print(round(x), round(x, 2), round(1250, -2), round(-25, -1), round(155, n))
+`
+	want := "7 7 1200 -20 160\n"
+	if got := runPython(t, source); got != want {
+		t.Fatalf("python printed\n%s", got)
+	}
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestTryThatCantRaiseRunsItsBody(t *testing.T) {
+	source := `x: int = 1
+try:
+    x += 1
+except Exception:
+    print("never")
+print(x)
+`
+	expectOutput(t, source, Settings{}, "2\n")
+	expectOutput(t, source, Settings{goSemantics: true}, "2\n")
+}
+
+func TestBareRaiseInNestedTry(t *testing.T) {
+	source := `def f(n: int) -> int:
+    if n < 0:
+        raise ValueError("neg")
+    return n
+
+try:
+    try:
+        print(f(-1))
+    except ValueError:
+        print("inner")
+        raise
+except ValueError as e:
+    print("outer", e)
+`
+	expectOutput(t, source, Settings{}, "inner\nouter neg\n")
+}
+
+// Runs a program with CPython, using the shims in TypingSystem
+func runPython(t *testing.T, source string) string {
+	t.Helper()
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 isn't installed")
+	}
+	shims, err := filepath.Abs(filepath.Join("..", "TypingSystem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "prog.py"), []byte(source), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	run := exec.CommandContext(ctx, python, "prog.py")
+	run.Dir = dir
+	run.Env = append(os.Environ(), "PYTHONPATH="+shims)
+	printed, err := run.CombinedOutput()
+	if ctx.Err() != nil {
+		t.Fatalf("python3 didn't finish\n%s", printed)
+	}
+	if err != nil {
+		t.Fatalf("%v\n%s", err, printed)
+	}
+	return string(printed)
+}
+
+func TestBuiltinsAsSortKeys(t *testing.T) {
+	source := `words: list[string] = ["ccc", "a", "bb"]
+print(sorted(words, key=len), sorted(words, key=len, reverse=True))
+`
+	expectOutput(t, source, Settings{}, "['a', 'bb', 'ccc'] ['ccc', 'bb', 'a']\n")
+
+	sources := map[string]string{
+		"conv: Callable[[string], int] = lambda s: int(s)\nprint(conv(\"1\"))\n": "a lambda can't",
+		"size: Callable[[string], int] = len\nprint(size(\"ab\"))\n":             "can't be used as a value",
+	}
+	for source, want := range sources {
+		_, err := compileSource(t.TempDir(), source, Settings{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q for %q, got %v", want, source, err)
+		}
+	}
+}
+
+func TestRangeStepIsReadOnceAndNotZero(t *testing.T) {
+	source := `def count(a: int, b: int, s: int) -> int:
+    n: int = 0
+    for i in range(a, b, s):
+        s = s * 2
+        n += i
+    return n
+
+print(count(0, 10, 2), count(10, 0, -3))
+try:
+    print(count(0, 10, 0))
+except ValueError as e:
+    print("caught", e)
+`
+	want := "20 22\ncaught range() arg 3 must not be zero\n"
+	if got := runPython(t, source); got != want {
+		t.Fatalf("python printed\n%s", got)
+	}
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestSplitRejectsAnEmptySeparator(t *testing.T) {
+	source := `from GoType import *
+
+line: string = "a,b,,c"
+sep: string = ","
+print(line.split(sep), "x y".split())
+sep = ""
+try:
+    print(line.split(sep))
+except ValueError as e:
+    print("caught", e)
+`
+	want := "['a', 'b', '', 'c'] ['x', 'y']\ncaught empty separator\n"
+	if got := runPython(t, source); got != want {
+		t.Fatalf("python printed\n%s", got)
+	}
+	expectOutput(t, source, Settings{}, want)
+
+	_, err := compileSource(t.TempDir(), "x: string = \"ab\"\nprint(x.split(\"\"))\n", Settings{})
+	if err == nil || !strings.Contains(err.Error(), "empty separator") {
+		t.Errorf("expected the empty separator to be reported, got %v", err)
+	}
+}
+
+func TestSelectReceivesUnbufferedSend(t *testing.T) {
+	source := `from GoType import *
+
+def worker(ch: Chan[int], done: Chan[int]) -> None:
+    i, v = select(recv(ch))
+    print("got", i, v)
+    done.send(1)
+
+ch: Chan[int] = make_chan()
+done: Chan[int] = make_chan()
+go(worker, ch, done)
+ch.send(7)
+done.recv()
+`
+	want := "got 0 7\n"
+	if got := runPython(t, source); got != want {
+		t.Errorf("python3 printed\n%s\nwant\n%s", got, want)
+	}
+	expectOutput(t, source, Settings{}, want)
+}
+
+func TestDictsKeepInsertionOrder(t *testing.T) {
+	source := `def show(d: dict[string, int]) -> None:
+    for k, v in d.items():
+        print(k, v)
+
+d: dict[string, int] = {"b": 1, "a": 2, "c": 3, "a": 4}
+print(d)
+show(d)
+for i, k in enumerate(d):
+    print(i, k)
+print(sorted(d), len(d))
+`
+	want := "{'b': 1, 'a': 4, 'c': 3}\nb 1\na 4\nc 3\n0 b\n1 a\n2 c\n['a', 'b', 'c'] 3\n"
+	expectOutput(t, source, Settings{}, want)
+}
+
+func TestBreakAndContinueLeaveWith(t *testing.T) {
+	source := `class Res:
+    name: string = ""
+
+    def __init__(self, name: string) -> None:
+        self.name = name
+
+    def __enter__(self) -> Res:
+        return self
+
+    def __exit__(self, *args: any) -> None:
+        print("exit", self.name)
+
+def find(items: list[int], target: int) -> int:
+    for i, x in enumerate(items):
+        with Res("f") as r:
+            if x == target:
+                return i
+            if x < 0:
+                break
+    return -1
+
+for i in range(4):
+    with Res("a") as a:
+        if i == 1:
+            continue
+        with Res("b") as b:
+            if i == 2:
+                break
+        print("body", i)
+else:
+    print("no break")
+print(find([5, -1, 7], 7))
+`
+	want := "exit b\nbody 0\nexit a\nexit a\nexit b\nexit a\nexit f\nexit f\n-1\n"
+	expectOutput(t, source, Settings{}, want)
+}
+
+func TestFloatConstantsRoundLikePython(t *testing.T) {
+	source := `x: float64 = 0.1 + 0.2
+print(0.1 + 0.2, x, 1 / 3 * 3, 2 / 3)
+if 0.1 + 0.2 == 0.3:
+    print("equal")
+else:
+    print("not equal")
+`
+	want := "0.30000000000000004 0.30000000000000004 1.0 0.6666666666666666\nnot equal\n"
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestDivisionByZeroConstantIsReported(t *testing.T) {
+	sources := []string{
+		"print(1.0 / 0)\n",
+		"x: float64 = 2.0\nprint(x / 0.0)\n",
+		"n: int = 3\nprint(n // 0)\n",
+		"n: int = 5\nn %= 1 - 1\n",
+	}
+	for i := 0; i < len(sources); i++ {
+		_, err := compileSource(t.TempDir(), sources[i], Settings{})
+		if err == nil || !strings.Contains(err.Error(), "by zero") {
+			t.Errorf("expected division by zero to be reported for %q, got %v", sources[i], err)
+		}
+	}
+}
+
+func TestDivisionByZeroRaises(t *testing.T) {
+	source := `from GoType import *
+
+def ratio(a: int, b: int) -> float64:
+    return a / b
+
+def kind(n: int, d: int) -> string:
+    if n < 0:
+        return "negative"
+    elif n % d == 0:
+        return "divisible"
+    return "other"
+
+z: int = 0
+a: int = 7
+f: float64 = 0.0
+try:
+    print(a // z)
+except ZeroDivisionError as e:
+    print("caught", e)
+try:
+    print(ratio(a, z))
+except ZeroDivisionError as e:
+    print("caught", e)
+try:
+    print(2.5 % f)
+except ZeroDivisionError as e:
+    print("caught", e)
+try:
+    q, r = divmod(a, z)
+    print(q, r)
+except ZeroDivisionError as e:
+    print("caught", e)
+try:
+    a //= z
+except ZeroDivisionError as e:
+    print("caught", e)
+try:
+    print(kind(a, z))
+except ZeroDivisionError as e:
+    print("caught", e)
+n: int = 120
+while n % a != 0:
+    n = n // 2
+print(kind(-1, 0), kind(9, 3), a // 2, n)
+`
+	want := "caught integer division or modulo by zero\ncaught division by zero\ncaught float modulo\ncaught integer division or modulo by zero\ncaught integer division or modulo by zero\ncaught integer modulo by zero\nnegative divisible 3 7\n"
+	if got := runPython(t, source); got != want {
+		t.Fatalf("python printed\n%s", got)
+	}
+	expectOutput(t, source, Settings{}, want)
+	expectOutput(t, source, Settings{bigInts: true}, want)
+}
+
+func TestFromImportedFunctions(t *testing.T) {
+	source := `from math import sqrt
+from math import floor
+
+def twice(v: float64) -> float64:
+    return sqrt(v) * 2
+
+print(sqrt(4.0), floor(2.5), twice(2))
+`
+	expectOutput(t, source, Settings{}, "2.0 2 2.8284271247461903\n")
+
+	_, err := compileSource(t.TempDir(), "from math import pi\nprint(pi)\n", Settings{})
+	if err == nil || !strings.Contains(err.Error(), "math.pi") {
+		t.Errorf("expected the import of pi to be reported, got %v", err)
+	}
+}
+
+// Writes the local modules of a program, with \r\n line endings
+func writeModules(t *testing.T, dir string, modules map[string]string) {
+	t.Helper()
+	for name, source := range modules {
+		err := os.WriteFile(filepath.Join(dir, name+".py"), []byte(strings.ReplaceAll(source, "\n", "\r\n")), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModulesShareNamesAndExposeVariables(t *testing.T) {
+	modules := map[string]string{
+		"utils": `LIMIT: int = 3
+
+class Box:
+    n: int = 0
+
+def helper(x: int) -> int:
+    return x + LIMIT
+
+def bump() -> None:
+    global LIMIT
+    LIMIT = LIMIT + 1
+
+def make(n: int) -> Box:
+    b: Box = Box()
+    b.n = n
+    return b
+`,
+		"other": `def helper(x: int) -> int:
+    return x * 100
+`,
+	}
+	source := `import utils
+from utils import LIMIT
+from utils import Box
+from other import helper
+
+def show(b: Box) -> int:
+    return b.n + LIMIT
+
+print(helper(1), utils.helper(1), LIMIT, utils.LIMIT)
+utils.bump()
+print(LIMIT, utils.LIMIT, utils.helper(1))
+b: Box = utils.make(5)
+print(show(b), isinstance(b, Box))
+`
+	dir := t.TempDir()
+	writeModules(t, dir, modules)
+	want := "100 4 3 3\n3 4 5\n8 True\n"
+	if got := runProgramIn(t, dir, source, Settings{}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	errors := map[string]string{
+		"import utils\nprint(utils.NOPE)\n":                              "prog.py -> line: 2",
+		"import utils\nutils.LIMIT = 5\n":                                "can only be assigned to in its own module",
+		"from utils import helper\ndef helper() -> int:\n    return 1\n": "can't be defined here too",
+		"import utils\nx: int = utils.helper(\"a\")\n":                   "prog.py -> line: 2",
+	}
+	for program, want := range errors {
+		_, err := compileSource(dir, program, Settings{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q for %q, got %v", want, program, err)
+		}
+	}
+
+	writeModules(t, dir, map[string]string{"broken": "def bad() -> int:\n    return \"x\"\n"})
+	_, err := compileSource(dir, "import broken\n", Settings{})
+	if err == nil || !strings.Contains(err.Error(), "broken.py -> line: 2") {
+		t.Errorf("expected the error to be in broken.py, got %v", err)
+	}
+}
+
+func TestEntryVariablesAreReadInFunctions(t *testing.T) {
+	source := `from GoType import *
+
+LIMIT: int = 3
+n: int = 5
+name: string = "pogo"
+wg: WaitGroup = WaitGroup()
+
+def over() -> int:
+    return LIMIT + 1
+
+def shout() -> string:
+    return name.upper()
+
+def twice(n: int) -> int:
+    return n * 2
+
+def finish() -> None:
+    wg.done()
+
+def bump() -> None:
+    global LIMIT
+    LIMIT = LIMIT + 1
+
+wg.add(1)
+finish()
+wg.wait()
+print(over(), shout(), twice(1), n)
+bump()
+print(over())
+`
+	expectOutput(t, source, Settings{}, "4 POGO 2 5\n5\n")
+}
+
+// Writes the files of a project, by their path in dir, with Python files
+// given \r\n line endings
+func writeProject(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, ".py") {
+			source = strings.ReplaceAll(source, "\n", "\r\n")
+		}
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(source), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Builds a project, then runs the Go module it was built to in out, giving
+// the modules that were compiled and what the program printed
+func runProject(t *testing.T, dir string) ([]string, string) {
+	t.Helper()
+	compiled, err := buildProject(dir, Settings{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := exec.Command("go", "run", ".")
+	run.Dir = filepath.Join(dir, "out")
+	printed, err := run.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, printed)
+	}
+	return compiled, string(printed)
+}
+
+func TestModuleFilesKeepTheirNames(t *testing.T) {
+	dir := t.TempDir()
+	writeProject(t, dir, map[string]string{
+		"pogo.toml":       "module = \"example.com/p\"\noutput = \"out\"\n",
+		"main.py":         "import calc_test\nimport io_windows\nimport pogo_runtime\nprint(calc_test.one() + io_windows.two() + pogo_runtime.three())\n",
+		"calc_test.py":    "def one() -> int:\n    return 1\n",
+		"io_windows.py":   "def two() -> int:\n    return 2\n",
+		"pogo_runtime.py": "def three() -> int:\n    return 3\n",
+	})
+
+	if _, printed := runProject(t, dir); printed != "6\n" {
+		t.Errorf("got %q", printed)
+	}
+	for _, name := range []string{"main_py.go", "calc_test_py.go", "io_windows_py.go", "pogo_runtime_py.go", "pogo_runtime.go"} {
+		if _, err := os.Stat(filepath.Join(dir, "out", name)); err != nil {
+			t.Errorf("expected %s to be written", name)
+		}
+	}
+}
+
+func TestBuildKeepsHandWrittenFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeProject(t, dir, map[string]string{
+		"pogo.toml": "module = \"example.com/p\"\noutput = \"out\"\n",
+		"main.py":   "import utils\nprint(utils.one())\n",
+		"utils.py":  "def one() -> int:\n    return 1\n",
+	})
+	runProject(t, dir)
+	cached := filepath.Join(dir, ".pogo-cache", "utils.json")
+	if _, err := os.Stat(cached); err != nil {
+		t.Fatal(err)
+	}
+
+	// A go.mod written by hand is kept, and the cache of a module that is
+	// gone is removed
+	goMod := "module example.com/p\n\ngo 1.21\n"
+	writeProject(t, dir, map[string]string{
+		"out/go.mod": goMod,
+		"main.py":    "print(2)\n",
+	})
+	os.Remove(filepath.Join(dir, "utils.py"))
+	if _, printed := runProject(t, dir); printed != "2\n" {
+		t.Errorf("got %q", printed)
+	}
+	if source, _ := os.ReadFile(filepath.Join(dir, "out", "go.mod")); string(source) != goMod {
+		t.Errorf("expected go.mod to be kept, got %q", source)
+	}
+	if _, err := os.Stat(cached); err == nil {
+		t.Errorf("expected the cache of utils to be removed")
+	}
+}
+
+func TestCachedModulesAreCompiledWhenTheirImportsChange(t *testing.T) {
+	dir := t.TempDir()
+	writeProject(t, dir, map[string]string{
+		"pogo.toml": "module = \"example.com/p\"\noutput = \"out\"\n",
+		"main.py": `import twice
+
+try:
+    print(twice.twice())
+except ValueError:
+    print("caught")
+`,
+		"twice.py": `from value import value
+
+def twice() -> int:
+    return value() * 2
+`,
+		"value.py": "def value() -> int:\n    return 1\n",
+	})
+
+	steps := []struct {
+		value    string
+		compiled string
+		printed  string
+	}{
+		{"", "main twice value", "2\n"},
+		{"", "", "2\n"},
+		// A body that changes leaves the modules that import it clean
+		{"def value() -> int:\n    return 5\n", "value", "10\n"},
+		// A function that starts to raise changes the signatures, so twice,
+		// whose source is the same, is compiled again to pass the error on
+		{"limit: int = 0\n\ndef value() -> int:\n    if limit == 0:\n        raise ValueError(\"bad\")\n    return limit\n", "main twice value", "caught\n"},
+	}
+	for i, step := range steps {
+		if step.value != "" {
+			writeProject(t, dir, map[string]string{"value.py": step.value})
+		}
+		compiled, printed := runProject(t, dir)
+		sort.Strings(compiled)
+		if strings.Join(compiled, " ") != step.compiled || printed != step.printed {
+			t.Errorf("step %d compiled %v and printed %q, want %q and %q", i, compiled, printed, step.compiled, step.printed)
+		}
+	}
+}
+
+func TestBuildsAreTheSame(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pogo.toml": "module = \"example.com/p\"\noutput = \"out\"\n",
+		"main.py":   "",
+	}
+	prints := ""
+	for i := 0; i < 8; i++ {
+		name := "m" + strconv.Itoa(i)
+		files["main.py"] += "import " + name + "\n"
+		prints += "print(" + name + ".f(" + strconv.Itoa(i) + "))\n"
+		files[name+".py"] = "from math import sqrt\n\ndef f(x: int) -> string:\n    return str(sqrt(x)).upper()\n"
+	}
+	files["main.py"] += prints
+	writeProject(t, dir, files)
+
+	// Modules are emitted in parallel, but the files written don't depend on
+	// the order they finish in, nor on whether they came from the cache
+	builds := []map[string]string{}
+	for i := 0; i < 3; i++ {
+		if i == 1 {
+			os.RemoveAll(filepath.Join(dir, ".pogo-cache"))
+		}
+		if _, err := buildProject(dir, Settings{}, false); err != nil {
+			t.Fatal(err)
+		}
+		paths, _ := filepath.Glob(filepath.Join(dir, "out", "*"))
+		build := map[string]string{}
+		for _, path := range paths {
+			source, _ := os.ReadFile(path)
+			build[filepath.Base(path)] = string(source)
+		}
+		builds = append(builds, build)
+	}
+	if len(builds[0]) != 11 {
+		t.Errorf("expected 11 files, got %d", len(builds[0]))
+	}
+	for i := 1; i < len(builds); i++ {
+		if !reflect.DeepEqual(builds[0], builds[i]) {
+			t.Errorf("build %d wrote different files", i)
+		}
+	}
+}
+
+func TestWatchLooksInEverySourceDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "project")
+	writeProject(t, root, map[string]string{
+		"project/pogo.toml":    "module = \"example.com/p\"\nsources = [\"../shared\"]\noutput = \"out\"\n",
+		"project/main.py":      "print(1)\n",
+		"project/out/stale.py": "print(2)\n",
+		"shared/utils.py":      "print(3)\n",
+	})
+
+	times := sourceTimes(dir)
+	for _, name := range []string{"project/pogo.toml", "project/main.py", "shared/utils.py"} {
+		if _, found := times[filepath.Join(root, name)]; !found {
+			t.Errorf("expected %s to be watched", name)
+		}
+	}
+	if _, found := times[filepath.Join(root, "project/out/stale.py")]; found || len(times) != 3 {
+		t.Errorf("expected only the sources to be watched, got %v", times)
+	}
+}
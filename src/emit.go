@@ -2,11 +2,1550 @@ package main
 
 import (
 	"errors"
+	"go/types"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type Emitter struct {
+	imports   []string                  // Go packages the emitted code needs
+	modules   map[string]string         // Python modules in scope, by the name they are used with
+	helpers   []string                  // Runtime helpers the emitted code needs
+	raising   map[string]bool           // Functions that may raise, which return an error in Go
+	functions []FunctionContext         // The functions being emitted, innermost last
+	pre       []string                  // Code that has to run before the current statement
+	tries     []int                     // The try statements being emitted, innermost last
+	excepts   []int                     // The try statements whose except clauses are being emitted
+	count     int                       // For naming temporary variables and labels
+	classes   map[string]Class          // Classes the analyzer found
+	loops     []string                  // The labels breaks jump to, for loops with an else, innermost last
+	packages  map[string]*types.Package // Go packages the analyzer read, by their path
+	settings  Settings
+}
+
+// What the emitter needs to know about the function it is in
+type FunctionContext struct {
+	results []string // Go types of the results, without the error
+	raises  bool
+	scoped  bool // The closure of a with statement, which flags how it ended
+}
+
+// Marks a Go package as needed by the emitted code
+func (e *Emitter) require(pkg string) {
+	for i := 0; i < len(e.imports); i++ {
+		if e.imports[i] == pkg {
+			return
+		}
+	}
+	e.imports = append(e.imports, pkg)
+}
+
+// Resolves a Python module name that the source imported, returning the
+// name the Go package is referred to by. The package is only imported once
+// something uses it, as Go won't compile unused imports.
+func (e *Emitter) module(name string) (string, bool) {
+	module, exists := e.modules[name]
+	if !exists {
+		return "", false
+	}
+	pkg := goImports[module]
+	e.require(pkg)
+	return pkg[strings.LastIndex(pkg, "/")+1:], true
+}
+
+// The import declaration for Go packages, each written once
+func importBlock(packages []string) string {
+	if len(packages) == 0 {
+		return ""
+	}
+
+	sorted := append([]string{}, packages...)
+	sort.Strings(sorted)
+
+	output := "\nimport (\n"
+	for i := 0; i < len(sorted); i++ {
+		if i > 0 && sorted[i] == sorted[i-1] {
+			continue
+		}
+		output += "\t\"" + sorted[i] + "\"\n"
+	}
+	return output + ")\n"
+}
+
+func (e *Emitter) emitImport(ast Structure) error {
+	if e.modules == nil {
+		e.modules = map[string]string{}
+	}
+
+	if ast.children[0].code == structureCode["K_FROM"] {
+		// Annotations from typing, such as Callable, are only for the
+		// analyzer
+		if ast.children[1].text == "GoType" || ast.children[1].text == "typing" {
+			return nil
+		}
+		if ast.children[3].code == structureCode["ASTERISK"] {
+			return errors.New("[Emit (emitImport)] Cannot import * from \"" + ast.children[1].text + "\" on line " + strconv.Itoa(ast.line))
+		}
+	}
+
+	// Go packages are imported where they are used
+	name, alias := importNames(ast)
+	_, function := moduleFunctions[name]
+	if _, exists := goImports[name]; !exists && !function && !isGoModule(name) {
+		return errors.New("[Emit (emitImport)] No Go equivalent for the module \"" + name + "\" on line " + strconv.Itoa(ast.line))
+	}
+	e.modules[alias] = name
+	return nil
+}
+
+// Python's print, through fmt, formatting values the way Python does
+func (e *Emitter) emitPrint(ast Structure) (string, error) {
+	e.require("os")
+	e.helper("pyPrint")
+
+	writer := "os.Stdout"
+	sep := "\" \""
+	end := "\"\\n\""
+	args := ""
+
+	for i := 2; i < len(ast.children)-1; i += 2 {
+		arg := ast.children[i]
+		if arg.code != structureCode["KEYWORD_ARG"] {
+			temp, err := e.emit(arg)
+			if err != nil {
+				return "", err
+			}
+			args += "," + temp
+			continue
+		}
+
+		temp, err := e.emit(arg.children[2])
+		if err != nil {
+			return "", err
+		}
+		switch arg.children[0].text {
+		case "sep":
+			sep = temp
+		case "end":
+			end = temp
+		case "file":
+			writer = temp
+		}
+	}
+
+	return "pyPrint(" + writer + ", " + sep + ", " + end + args + ")", nil
+}
+
+// The parameters of a function, with the variadic parameter moved to the
+// end as Go requires. Also gives the arguments that pass them on
+func (e *Emitter) parameters(ast Structure, method bool) ([]string, []string) {
+	params := []string{}
+	args := []string{}
+	variadic := ""
+	variadicType := ""
+
+	start := 3
+	if method {
+		start = 5 // Skipping self, which is the receiver
+	}
+	for i := start; ast.children[i].code == structureCode["PARAMETER"]; i += 2 {
+		param := ast.children[i]
+		if param.children[0].code == structureCode["MO_MUL"] {
+			variadic = param.children[1].text
+			variadicType = e.goType(param.children[3].text)
+			continue
+		}
+		params = append(params, param.children[0].text+" "+e.goType(param.children[2].text))
+		args = append(args, param.children[0].text)
+	}
+
+	if variadic != "" {
+		params = append(params, variadic+" ..."+variadicType)
+		args = append(args, variadic+"...")
+	}
+	return params, args
+}
+
+// Functions, and methods when there's a receiver. The package variables of
+// globals come before the top level code they are declared in
+func (e *Emitter) emitFunction(ast Structure, receiver string) (string, error) {
+	signature, body, err := e.functionParts(ast, receiver)
+	if err != nil {
+		return "", err
+	}
+	output := "\nfunc " + receiver + ast.children[1].text + signature + " " + body
+	if ast.line == -1 {
+		output = e.globalVariables(ast.children[len(ast.children)-1]) + output
+	}
+	return output, nil
+}
+
+// The signature of a function, from its parameters, and its body
+func (e *Emitter) functionParts(ast Structure, receiver string) (string, string, error) {
+	params, _ := e.parameters(ast, receiver != "")
+	output := "(" + strings.Join(params, ", ") + ")"
+
+	// Only main lacks a return type, which is ARROW, the type, COLON, then BLOCK
+	context := FunctionContext{[]string{}, e.raising[ast.children[1].text] && receiver == "", false}
+	returnType := ast.children[len(ast.children)-3]
+	if ast.children[len(ast.children)-4].code == structureCode["ARROW"] && returnType.code != structureCode["L_NULL"] {
+		base, args := typeArguments(returnType.text)
+		if base != "tuple" {
+			args = []string{returnType.text}
+		}
+		for i := 0; i < len(args); i++ {
+			context.results = append(context.results, e.goType(args[i]))
+		}
+	}
+
+	results := context.results
+	if context.raises {
+		results = append(results, "error")
+	}
+	if len(results) == 1 {
+		output += " " + results[0]
+	} else if len(results) > 1 {
+		output += " (" + strings.Join(results, ", ") + ")"
+	}
+
+	// A closure is in its own function, outside the try statements and
+	// loops around it
+	tries, excepts, loops := e.tries, e.excepts, e.loops
+	e.tries, e.excepts, e.loops = []int{}, []int{}, []string{}
+	e.functions = append(e.functions, context)
+	temp, err := e.emit(ast.children[len(ast.children)-1])
+	e.functions = e.functions[:len(e.functions)-1]
+	e.tries, e.excepts, e.loops = tries, excepts, loops
+	if err != nil {
+		return output, "", err
+	}
+
+	// Falling off the end of a function that may raise means nothing was.
+	// Functions with results need to end in a return for Go, even when
+	// every path inside returns
+	if context.raises && len(context.results) == 0 {
+		temp = temp[:len(temp)-1] + "\nreturn nil\n}"
+	} else if len(context.results) > 0 && !endsInReturn(ast.children[len(ast.children)-1]) {
+		temp = temp[:len(temp)-1] + "\npanic(\"missing return\")\n}"
+	}
+	return output, temp, nil
+}
+
+// Blocks, placing any code statements need before them
+func (e *Emitter) emitBlock(ast Structure) (string, error) {
+	outer := e.pre
+	e.pre = []string{}
+
+	output := "{"
+	for i := 0; i < len(ast.children); i++ {
+		child := ast.children[i]
+
+		var temp string
+		var err error
+		if child.code == structureCode["ST_CALL"] && raisingCall(child, e.raising) {
+			temp, err = e.emitRaisingCall(child, true)
+		} else {
+			temp, err = e.emit(child)
+		}
+		if err != nil {
+			return output, err
+		}
+
+		output += " " + strings.Join(e.pre, "") + temp
+		e.pre = []string{}
+	}
+
+	e.pre = outer
+	return output, nil
+}
+
+// Calls to functions that may raise. The call is placed before the
+// statement it is in, so the error can be checked, with the results left in
+// temporary variables
+func (e *Emitter) emitRaisingCall(ast Structure, discard bool) (string, error) {
+	var call string
+	var err error
+	if ast.children[0].varType == "module" {
+		call, err = e.emitModuleCall(ast)
+	} else if builtinRaises(ast) && ast.children[0].code == structureCode["ATTRIBUTE"] {
+		call, err = e.emitMethod(ast)
+	} else if builtinRaises(ast) {
+		call, err = e.emitBuiltin(ast)
+	} else {
+		call, err = e.emitCall(ast)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	count := 1
+	if ast.varType == "None" {
+		count = 0
+	} else if isTuple(ast.varType) {
+		_, args := typeArguments(ast.varType)
+		count = len(args)
+	}
+
+	temps := []string{}
+	for i := 0; i < count; i++ {
+		if discard {
+			temps = append(temps, "_")
+			continue
+		}
+		e.count++
+		temps = append(temps, "pyTmp"+strconv.Itoa(e.count))
+	}
+
+	e.count++
+	errVar := "pyErr" + strconv.Itoa(e.count)
+	e.pre = append(e.pre, "\n"+strings.Join(append(temps, errVar), ", ")+" := "+call+"\nif "+errVar+" != nil {"+e.throw(errVar, ast.line)+"}\n")
+
+	if discard {
+		return "", nil
+	}
+	return strings.Join(temps, ", "), nil
+}
+
+// A call, as name(args). Classes are constructed with newName, and built in
+// functions are runtime helpers
+func (e *Emitter) emitCall(ast Structure) (string, error) {
+	args := []string{}
+	for i := 2; i < len(ast.children)-1; i += 2 {
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return "", err
+		}
+		args = append(args, temp)
+	}
+
+	name := ast.children[0].text
+	if e.classes[name].name != "" {
+		name = "new" + name
+	} else if ast.children[0].varType == "builtin" {
+		name = builtinHelpers[name]
+		e.helper(name)
+	}
+	return name + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// Built in functions, and the runtime helper for each
+var builtinHelpers map[string]string = map[string]string{
+	"open": "pyOpen",
+}
+
+// Where an exception goes, either to the except clauses of a try, out of
+// the function as an error, or to a traceback if there's nowhere else
+func (e *Emitter) throw(value string, line int) string {
+	if len(e.tries) > 0 {
+		n := strconv.Itoa(e.tries[len(e.tries)-1])
+		return "\npyErr" + n + " = " + value + "\ngoto pyExcept" + n + "\n"
+	}
+
+	context := e.functions[len(e.functions)-1]
+	if context.raises {
+		output := "\nreturn "
+		if context.scoped {
+			output += "0, "
+		}
+		for i := 0; i < len(context.results); i++ {
+			output += e.zeroValue(context.results[i]) + ", "
+		}
+		return output + value + "\n"
+	}
+
+	e.helper("pyTraceback")
+	return "\npyTraceback(" + value + ", " + strconv.Itoa(line) + ")\n"
+}
+
+func (e *Emitter) emitRaise(ast Structure) (string, error) {
+	if len(ast.children) == 1 {
+		return e.throw("pyErr"+strconv.Itoa(e.excepts[len(e.excepts)-1]), ast.line), nil
+	}
+
+	value, err := e.emit(ast.children[1])
+	if err != nil {
+		return "", err
+	}
+	return e.throw(value, ast.line), nil
+}
+
+// Try statements. Errors in the body jump to the except clauses, which
+// check the type of the error with errors.As, passing it on if none match
+func (e *Emitter) emitTry(ast Structure) (string, error) {
+	e.count++
+	number := e.count
+	n := strconv.Itoa(number)
+
+	e.tries = append(e.tries, number)
+	body, err := e.emit(ast.children[2])
+	e.tries = e.tries[:len(e.tries)-1]
+	if err != nil {
+		return "", err
+	}
+
+	// Nothing in the body raises, so the except clauses can never run
+	if !strings.Contains(body, "goto pyExcept"+n+"\n") {
+		return body, nil
+	}
+
+	e.excepts = append(e.excepts, number)
+	defer func() { e.excepts = e.excepts[:len(e.excepts)-1] }()
+
+	chain := ""
+	caught := false
+	for i := 3; i < len(ast.children) && !caught; i++ {
+		except := ast.children[i]
+		block, err := e.emit(except.children[len(except.children)-1])
+		if err != nil {
+			return "", err
+		}
+
+		if chain != "" {
+			chain += " else "
+		}
+
+		if len(except.children) == 3 || except.children[1].text == "Exception" {
+			caught = true
+			if len(except.children) == 6 {
+				name := except.children[3].text
+				block = "{\n" + name + " := pyErr" + n + "\n_ = " + name + "\n" + block[1:]
+			}
+			chain += block
+			continue
+		}
+
+		e.helper(except.children[1].text)
+		e.require("errors")
+		if len(except.children) == 6 {
+			name := except.children[3].text
+			chain += "if " + name + " := (*" + except.children[1].text + ")(nil); errors.As(pyErr" + n + ", &" + name + ") {\n_ = " + name + "\n" + block[1:]
+		} else {
+			chain += "if errors.As(pyErr" + n + ", new(*" + except.children[1].text + ")) " + block
+		}
+	}
+
+	if !caught {
+		chain += " else {" + e.throw("pyErr"+n, ast.line) + "}"
+	}
+
+	return "\nvar pyErr" + n + " error\n" + body + "\npyExcept" + n + ":\nif pyErr" + n + " != nil {\n" + chain + "\n}", nil
+}
+
+// A return from the current function. Functions that may raise also return
+// a nil error, and the closures of with statements flag that they returned
+func (e *Emitter) returnStatement(values []string) string {
+	context := e.functions[len(e.functions)-1]
+	if context.scoped {
+		values = append([]string{strconv.Itoa(withReturned)}, values...)
+	}
+	if context.raises {
+		values = append(values, "nil")
+	}
+	return "\nreturn " + strings.Join(values, ", ")
+}
+
+// Classes become a struct, a constructor called newName which sets the
+// fields and runs __init__, and methods on a pointer to the struct
+func (e *Emitter) emitClass(ast Structure) (string, error) {
+	name := ast.children[1].text
+	class := e.classes[name]
+
+	output := "\ntype " + name + " struct {\n"
+	for i := 0; i < len(class.fields); i++ {
+		output += class.fields[i].name + " " + e.goType(class.fields[i].varType) + "\n"
+	}
+	output += "}\n"
+
+	fields := []string{}
+	for i := 0; i < len(class.fields); i++ {
+		temp, err := e.emit(class.defaults[i])
+		if err != nil {
+			return output, err
+		}
+		fields = append(fields, class.fields[i].name+": "+temp)
+	}
+
+	methods := ""
+	params := []string{}
+	args := []string{}
+	body := ast.children[3]
+	for i := 0; i < len(body.children); i++ {
+		if body.children[i].code != structureCode["ST_FUNCTION"] {
+			continue
+		}
+		if body.children[i].children[1].text == "__init__" {
+			params, args = e.parameters(body.children[i], true)
+		}
+		temp, err := e.emitFunction(body.children[i], "(self *"+name+") ")
+		if err != nil {
+			return output, err
+		}
+		methods += "\n" + temp
+	}
+
+	output += "\nfunc new" + name + "(" + strings.Join(params, ", ") + ") *" + name + " {\n"
+	output += "self := &" + name + "{" + strings.Join(fields, ", ") + "}\n"
+	if _, exists := e.method(name, "__init__"); exists {
+		output += "self.__init__(" + strings.Join(args, ", ") + ")\n"
+	}
+	output += "return self\n}\n"
+
+	return output + methods, nil
+}
+
+func (e *Emitter) method(class string, name string) (Function, bool) {
+	methods := e.classes[class].methods
+	for i := 0; i < len(methods); i++ {
+		if methods[i].name == name {
+			return methods[i], true
+		}
+	}
+	return Function{}, false
+}
+
+// How the closure of a with statement ended, when it can end other than by
+// reaching the end of its body
+const (
+	withReturned = iota + 1
+	withBroke
+	withContinued
+)
+
+// With statements run in a closure, so the deferred cleanup happens when
+// the statement ends. The closure passes errors, returns, breaks and
+// continues on to the code around it
+func (e *Emitter) emitWith(ast Structure) (string, error) {
+	body := ast.children[len(ast.children)-1]
+	outer := e.functions[len(e.functions)-1]
+	returns := containsReturn(body)
+	breaks := leavesLoop(body, structureCode["ST_BREAK"])
+	continues := leavesLoop(body, structureCode["ST_CONTINUE"])
+
+	context := FunctionContext{[]string{}, mayRaise(ast, e.raising), returns || breaks || continues}
+	results := []string{}
+	if context.scoped {
+		context.results = outer.results
+		results = append([]string{"int"}, outer.results...)
+	}
+	if context.raises {
+		results = append(results, "error")
+	}
+
+	closure, err := e.withClosure(ast, context)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return "\nfunc() {" + closure + "}()", nil
+	}
+
+	declaration := results[0]
+	if len(results) > 1 {
+		declaration = "(" + strings.Join(results, ", ") + ")"
+	}
+	closure = "func() " + declaration + " {" + closure + "}()"
+
+	// What the closure gives back
+	e.count++
+	n := strconv.Itoa(e.count)
+	names := []string{}
+	values := []string{}
+	if context.scoped {
+		names = append(names, "pyRet"+n)
+		for i := 0; i < len(context.results); i++ {
+			e.count++
+			values = append(values, "pyTmp"+strconv.Itoa(e.count))
+		}
+		if returns {
+			names = append(names, values...)
+		} else {
+			for i := 0; i < len(values); i++ {
+				names = append(names, "_")
+			}
+		}
+	}
+	if context.raises {
+		names = append(names, "pyErr"+n)
+	}
+
+	output := "\n" + strings.Join(names, ", ") + " := " + closure + "\n"
+	if context.raises {
+		output += "if pyErr" + n + " != nil {" + e.throw("pyErr"+n, ast.line) + "}\n"
+	}
+	if returns {
+		output += "if pyRet" + n + " == " + strconv.Itoa(withReturned) + " {" + e.returnStatement(values) + "\n}\n"
+	}
+	if breaks {
+		output += "if pyRet" + n + " == " + strconv.Itoa(withBroke) + " {" + e.jump(structureCode["ST_BREAK"]) + "}\n"
+	}
+	if continues {
+		output += "if pyRet" + n + " == " + strconv.Itoa(withContinued) + " {" + e.jump(structureCode["ST_CONTINUE"]) + "}\n"
+	}
+	return output, nil
+}
+
+// The body of the closure of a with statement, which enters each context
+// manager and defers its exit
+func (e *Emitter) withClosure(ast Structure, context FunctionContext) (string, error) {
+	tries := e.tries
+	pre := e.pre
+	loops := e.loops
+	e.tries = []int{}
+	e.pre = []string{}
+	e.loops = []string{}
+	e.functions = append(e.functions, context)
+	defer func() {
+		e.functions = e.functions[:len(e.functions)-1]
+		e.tries = tries
+		e.pre = pre
+		e.loops = loops
+	}()
+
+	output := ""
+	for i := 1; ast.children[i].code == structureCode["WITH_ITEM"]; i += 2 {
+		item := ast.children[i]
+		value, err := e.emit(item.children[0])
+		if err != nil {
+			return "", err
+		}
+		e.count++
+		manager := "pyWith" + strconv.Itoa(e.count)
+		output += strings.Join(e.pre, "") + "\n" + manager + " := " + value + "\n"
+		e.pre = []string{}
+
+		// Files are their own context manager
+		entered := manager
+		exit := manager + ".Close()"
+		if item.children[0].varType != "file" {
+			entered = manager + ".__enter__()"
+			exit = manager + ".__exit__()"
+			if f, _ := e.method(item.children[0].varType, "__exit__"); !f.variadic {
+				exit = manager + ".__exit__(nil, nil, nil)"
+			}
+		}
+
+		if len(item.children) == 3 {
+			name := item.children[2].text
+			output += name + " := " + entered + "\n_ = " + name + "\n"
+		} else if entered != manager {
+			output += entered + "\n"
+		}
+		output += "defer " + exit + "\n"
+	}
+
+	body := ast.children[len(ast.children)-1]
+	block, err := e.emit(body)
+	if err != nil {
+		return "", err
+	}
+	output += block[1 : len(block)-1]
+
+	// Reaching the end means there was no return, and no error
+	if (context.scoped || context.raises) && !endsInReturn(body) {
+		values := []string{}
+		if context.scoped {
+			values = append(values, "0")
+		}
+		for i := 0; i < len(context.results); i++ {
+			values = append(values, e.zeroValue(context.results[i]))
+		}
+		if context.raises {
+			values = append(values, "nil")
+		}
+		output += "\nreturn " + strings.Join(values, ", ") + "\n"
+	}
+	return output, nil
+}
+
+// Whether a break or continue, given by its code, is somewhere in a
+// structure outside of the loops in it, so it leaves a loop around it. The
+// else clause of a loop is outside of the loop
+func leavesLoop(s Structure, code int) bool {
+	if s.code == code {
+		return true
+	}
+	if s.code == structureCode["ST_FUNCTION"] || s.code == structureCode["ST_CLASS"] {
+		return false
+	}
+	loop := s.code == structureCode["ST_FOR"] || s.code == structureCode["ST_WHILE"]
+	for i := 0; i < len(s.children); i++ {
+		if (!loop || s.children[i].code == structureCode["ST_ELSE"]) && leavesLoop(s.children[i], code) {
+			return true
+		}
+	}
+	return false
+}
+
+// A break or continue, given by its code. Outside of any loop it is in the
+// closure of a with statement, which flags it to the code around it
+func (e *Emitter) jump(code int) string {
+	if len(e.loops) == 0 {
+		values := []string{strconv.Itoa(withBroke)}
+		if code == structureCode["ST_CONTINUE"] {
+			values[0] = strconv.Itoa(withContinued)
+		}
+		context := e.functions[len(e.functions)-1]
+		for i := 0; i < len(context.results); i++ {
+			values = append(values, e.zeroValue(context.results[i]))
+		}
+		if context.raises {
+			values = append(values, "nil")
+		}
+		return "\nreturn " + strings.Join(values, ", ") + "\n"
+	}
+	if code == structureCode["ST_CONTINUE"] {
+		return "\ncontinue\n"
+	}
+	if label := e.loops[len(e.loops)-1]; label != "" {
+		return "\ngoto " + label + "\n"
+	}
+	return "\nbreak\n"
+}
+
+// Whether a return is somewhere in a structure
+func containsReturn(s Structure) bool {
+	if s.code == structureCode["ST_RETURN"] {
+		return true
+	}
+	for i := 0; i < len(s.children); i++ {
+		if containsReturn(s.children[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the last statement in a block returns or raises
+func endsInReturn(block Structure) bool {
+	for i := len(block.children) - 1; i >= 0; i-- {
+		switch block.children[i].code {
+		case structureCode["NEWLINE"], structureCode["ANTI_COLON"], structureCode["COMMENT_ONE"], structureCode["COMMENT_MULTI"]:
+			continue
+		case structureCode["ST_RETURN"], structureCode["ST_RAISE"]:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// The value a Go variable of a type starts with
+func (e *Emitter) zeroValue(t string) string {
+	if e.settings.bigInts && t == "int" {
+		return "nil"
+	}
+	if isNumeric(t) {
+		return "0"
+	}
+	switch t {
+	case "string":
+		return "\"\""
+	case "bool":
+		return "false"
+	}
+	return "*new(" + t + ")"
+}
+
+// Unpacking assignments. Targets the analyzer gave a type to are new
+// variables, which are declared first unless every target is new
+func (e *Emitter) emitUnpack(ast Structure) (string, error) {
+	output := ""
+	assign := false
+	for i := 0; i < len(ast.children); i++ {
+		child := ast.children[i]
+		if child.code == structureCode["ASSIGN"] {
+			assign = true
+			if child.text == "=" {
+				for j := 0; j < i; j += 2 {
+					if ast.children[j].varType != "" {
+						output = "var " + ast.children[j].text + " " + e.goType(ast.children[j].varType) + "\n" + output
+					}
+				}
+			}
+		}
+
+		if !assign || child.code != structureCode["EXPRESSION"] {
+			output += " " + child.text
+			continue
+		}
+
+		temp, err := e.emit(child)
+		if err != nil {
+			return output, err
+		}
+		output += " " + temp
+	}
+	return output, nil
+}
+
+// The concurrency functions from GoType, written as Go's statements for
+// them
+func (e *Emitter) emitConcurrency(ast Structure) (string, error) {
+	switch ast.children[0].text {
+	case "go", "defer":
+		temp, err := e.emit(ast.children[2])
+		if err != nil {
+			return "", err
+		}
+		return "\n" + ast.children[0].text + " " + temp, nil
+	case "make_chan":
+		base, _ := typeArguments(ast.varType)
+		if base != "Chan" {
+			return "", errors.New("[Emit (emitConcurrency)] The element type of the channel can't be worked out, store it in a variable with a type on line " + strconv.Itoa(ast.line))
+		}
+		if len(ast.children) == 3 {
+			return "make(" + e.goType(ast.varType) + ")", nil
+		}
+		temp, err := e.emit(ast.children[2])
+		if err != nil {
+			return "", err
+		}
+		return "make(" + e.goType(ast.varType) + ", " + temp + ")", nil
+	case "WaitGroup":
+		e.require("sync")
+		return "&sync.WaitGroup{}", nil
+	case "select":
+		return e.emitSelect(ast)
+	}
+	return "", errors.New("[Emit (emitConcurrency)] " + ast.children[0].text + " can only be used as a case of select on line " + strconv.Itoa(ast.line))
+}
+
+// Selects are placed before the statement they are in, leaving the index of
+// the case that ran, and the value received, in temporary variables
+func (e *Emitter) emitSelect(ast Structure) (string, error) {
+	e.count++
+	index := "pyTmp" + strconv.Itoa(e.count)
+	code := "\nvar " + index + " int\n"
+
+	value := ""
+	if isTuple(ast.varType) {
+		_, types := typeArguments(ast.varType)
+		e.count++
+		value = "pyTmp" + strconv.Itoa(e.count)
+		code += "var " + value + " " + e.goType(types[1]) + "\n"
+	}
+
+	code += "select {\n"
+	for i := 2; i+1 < len(ast.children); i += 2 {
+		c := ast.children[i].children[0]
+
+		args := []string{}
+		for j := 2; j+1 < len(c.children); j += 2 {
+			temp, err := e.emit(c.children[j])
+			if err != nil {
+				return "", err
+			}
+			args = append(args, temp)
+		}
+
+		switch c.children[0].text {
+		case "recv":
+			if value == "" {
+				code += "case <-" + args[0] + ":\n"
+			} else {
+				code += "case " + value + " = <-" + args[0] + ":\n"
+			}
+		case "send":
+			code += "case " + args[0] + " <- " + args[1] + ":\n"
+		case "default":
+			code += "default:\n"
+		}
+		code += index + " = " + strconv.Itoa(i/2-1) + "\n"
+	}
+	e.pre = append(e.pre, code+"}\n")
+
+	if value == "" {
+		return index, nil
+	}
+	return index + ", " + value, nil
+}
+
+// Method calls, using the type of the receiver the analyzer found
+func (e *Emitter) emitMethod(ast Structure) (string, error) {
+	callee := ast.children[0]
+	if callee.varType == "module" {
+		return e.emitModuleCall(ast)
+	}
+	receiver := callee.children[0].text
+	method := callee.children[2].text
+	if callee.children[0].code != structureCode["IDENTIFIER"] {
+		temp, err := e.emit(callee.children[0])
+		if err != nil {
+			return "", err
+		}
+		receiver = strings.TrimSpace(temp)
+	}
+
+	args := []string{}
+	for i := 2; i+1 < len(ast.children); i += 2 {
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return "", err
+		}
+		args = append(args, strings.TrimSpace(temp))
+	}
+
+	base, _ := typeArguments(callee.varType)
+	if e.classes[base].name != "" {
+		return receiver + "." + method + "(" + strings.Join(args, ", ") + ")", nil
+	}
+	if base == "string" && builtinRaises(ast) {
+		e.helper("pySplit")
+		return "pySplit(" + receiver + ", " + args[0] + ")", nil
+	}
+	if base == "string" {
+		return e.stringMethod(method, receiver, args), nil
+	}
+
+	switch base + "." + method {
+	case "file.read":
+		e.helper("pyRead")
+		return "pyRead(" + receiver + ")", nil
+	case "file.write":
+		e.helper("pyWrite")
+		return "pyWrite(" + receiver + ", " + args[0] + ")", nil
+	case "Chan.send":
+		return "\n" + receiver + " <- " + args[0], nil
+	case "Chan.recv":
+		return "<-" + receiver, nil
+	case "Chan.close":
+		return "\nclose(" + receiver + ")", nil
+	}
+
+	goName, exists := goMethods[base+"."+method]
+	if !exists {
+		return "", errors.New("[Emit (emitMethod)] No Go equivalent for the method \"" + method + "\" of " + callee.varType + " on line " + strconv.Itoa(ast.line))
+	}
+	return receiver + "." + goName + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// Methods, and the name of their Go equivalent
+var goMethods map[string]string = map[string]string{
+	"file.close":     "Close",
+	"WaitGroup.add":  "Add",
+	"WaitGroup.done": "Done",
+	"WaitGroup.wait": "Wait",
+}
+
+// Methods of str, and the function from the strings package that takes the
+// string and then the same arguments
+var stringFunctions map[string]string = map[string]string{
+	"count":      "strings.Count",
+	"endswith":   "strings.HasSuffix",
+	"lower":      "strings.ToLower",
+	"lstrip":     "strings.TrimLeft",
+	"replace":    "strings.ReplaceAll",
+	"rstrip":     "strings.TrimRight",
+	"split":      "strings.Split",
+	"startswith": "strings.HasPrefix",
+	"strip":      "strings.Trim",
+	"upper":      "strings.ToUpper",
+}
+
+// Methods of str that check every character, and the check from unicode
+var characterClasses map[string]string = map[string]string{
+	"isalpha": "unicode.IsLetter",
+	"isdigit": "unicode.IsDigit",
+	"isspace": "unicode.IsSpace",
+}
+
+// Methods of str, which the analyzer has checked. Without an argument, strip
+// and split work on whitespace, which the strings package has functions for
+func (e *Emitter) stringMethod(method string, receiver string, args []string) string {
+	e.require("strings")
+	switch {
+	case method == "join":
+		return "strings.Join(" + args[0] + ", " + receiver + ")"
+	case method == "split" && len(args) == 0:
+		return "strings.Fields(" + receiver + ")"
+	case method == "strip" && len(args) == 0:
+		return "strings.TrimSpace(" + receiver + ")"
+	case method == "lstrip" && len(args) == 0:
+		e.require("unicode")
+		return "strings.TrimLeftFunc(" + receiver + ", unicode.IsSpace)"
+	case method == "rstrip" && len(args) == 0:
+		e.require("unicode")
+		return "strings.TrimRightFunc(" + receiver + ", unicode.IsSpace)"
+	case method == "find":
+		e.helper("pyFind")
+		return e.pythonInt("pyFind(" + receiver + ", " + args[0] + ")")
+	case method == "count":
+		return e.pythonInt("strings.Count(" + receiver + ", " + args[0] + ")")
+	case characterClasses[method] != "":
+		e.require("unicode")
+		e.helper("pyStrIs")
+		return "pyStrIs(" + receiver + ", " + characterClasses[method] + ")"
+	}
+	return stringFunctions[method] + "(" + strings.Join(append([]string{receiver}, args...), ", ") + ")"
+}
+
+// Attributes of modules, written as the Go equivalent
+func (e *Emitter) emitAttribute(ast Structure) (string, error) {
+	name := ""
+	for i := 0; i < len(ast.children); i++ {
+		name += ast.children[i].text
+	}
+
+	// Fields of objects are the same in Go
+	if ast.children[0].varType != "" {
+		return name, nil
+	}
+
+	if module := moduleName(ast, e.modules); isGoModule(module) {
+		return e.emitGoValue(module), nil
+	}
+
+	goName, exists := goAttributes[name]
+	if !exists {
+		return "", errors.New("[Emit (emitAttribute)] No Go equivalent for \"" + name + "\" on line " + strconv.Itoa(ast.line))
+	}
+
+	module := ast.children[0].text
+	pkg, exists := e.module(module)
+	if !exists {
+		return "", errors.New("[Emit (emitAttribute)] The module \"" + module + "\" was used without being imported on line " + strconv.Itoa(ast.line))
+	}
+	return pkg + goName[strings.Index(goName, "."):], nil
+}
+
+// List and dict literals, with the type the analyzer gave them
+func (e *Emitter) emitLiteral(ast Structure) (string, error) {
+	if base, args := typeArguments(ast.varType); base == "dict" && len(ast.children) > 2 {
+		return e.emitDict(ast, args)
+	}
+	output := e.goType(ast.varType) + "{"
+	for i := 1; i+1 < len(ast.children); i++ {
+		if ast.children[i].code == structureCode["COLON"] || ast.children[i].code == structureCode["SEP"] {
+			output += ast.children[i].text + " "
+			continue
+		}
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return output, err
+		}
+		output += strings.TrimSpace(temp)
+	}
+	return output + "}", nil
+}
+
+// A dict literal, built from its keys and values in order, so the dict keeps
+// the order they were written in
+func (e *Emitter) emitDict(ast Structure, args []string) (string, error) {
+	keys := []string{}
+	values := []string{}
+	for i := 1; i+1 < len(ast.children); i++ {
+		if ast.children[i].code == structureCode["COLON"] || ast.children[i].code == structureCode["SEP"] {
+			continue
+		}
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return "", err
+		}
+		if ast.children[i-1].code == structureCode["COLON"] {
+			values = append(values, strings.TrimSpace(temp))
+		} else {
+			keys = append(keys, strings.TrimSpace(temp))
+		}
+	}
+	e.helper("pyDict")
+	return "pyNewDict([]" + e.goType(args[0]) + "{" + strings.Join(keys, ", ") + "}, []" + e.goType(args[1]) + "{" + strings.Join(values, ", ") + "})", nil
+}
+
+// For loops, written as the Go loop that fits what is looped over. Python
+// works out what is looped over once, so anything that could change while
+// looping is stored first
+func (e *Emitter) emitFor(ast Structure) (string, error) {
+	names := []Structure{}
+	i := 1
+	for ; ; i += 2 {
+		names = append(names, ast.children[i])
+		if ast.children[i+1].code != structureCode["SEP"] {
+			break
+		}
+	}
+	iterable := ast.children[i+2]
+	body := ast.children[i+4]
+
+	block, after, err := e.loopBody(ast, i+4)
+	if err != nil {
+		return "", err
+	}
+
+	// Values that are given to the names at the start of each loop, when
+	// the loop can't give them directly
+	bindings := ""
+	bind := func(name Structure, value string) {
+		if uses(body, name.text) {
+			bindings += "\n" + name.text + " := " + value
+		}
+	}
+	temp := func() string {
+		e.count++
+		return "pyTmp" + strconv.Itoa(e.count)
+	}
+	loop := func(header string) string {
+		return "\nfor " + header + " {" + bindings + "\n" + block[1:] + after
+	}
+
+	if len(iterable.children) != 1 || iterable.children[0].code != structureCode["ST_CALL"] || iterable.children[0].children[0].varType != "loop" {
+		source, err := e.emit(iterable)
+		if err != nil {
+			return "", err
+		}
+		if iterable.varType == "string" {
+			value := temp()
+			bind(names[0], "string("+value+")")
+			return loop("_, " + value + " := range " + source), nil
+		}
+		if base, _ := typeArguments(iterable.varType); base == "dict" {
+			source += ".keys"
+		}
+		return loop("_, " + loopName(names[0], body) + " := range " + source), nil
+	}
+	call := iterable.children[0]
+
+	// The items, keys and values of a dict
+	if call.children[0].code == structureCode["ATTRIBUTE"] {
+		dict := call.children[0].children[0].text
+		key := loopName(names[0], body)
+		switch call.children[0].children[2].text {
+		case "items":
+			if key == "_" && uses(body, names[1].text) {
+				key = temp()
+			}
+			bind(names[1], dict+".m["+key+"]")
+		case "values":
+			key = "_"
+			if uses(body, names[0].text) {
+				key = temp()
+			}
+			bind(names[0], dict+".m["+key+"]")
+		}
+		return loop("_, " + key + " := range " + dict + ".keys"), nil
+	}
+
+	// The counter of a range, which is hidden if the loop changes the name
+	// it gives, as that doesn't change the count in Python
+	counter := func() string {
+		if !uses(body, names[0].text) || assigns(body, names[0].text) {
+			hidden := temp()
+			bind(names[0], hidden)
+			return hidden
+		}
+		return names[0].text
+	}
+
+	switch call.children[0].text {
+	case "range":
+		header, err := e.rangeClause(call, counter(), names[0].varType, body)
+		if err != nil {
+			return "", err
+		}
+		return loop(header), nil
+	case "enumerate":
+		source, convert, err := e.indexSource(call.children[2])
+		if err != nil {
+			return "", err
+		}
+		index := loopName(names[0], body)
+		if len(call.children) > 4 || e.settings.bigInts {
+			value := ""
+			index = "_"
+			if uses(body, names[0].text) {
+				index = temp()
+				value = index
+			}
+			// Big ints count with an int64, which Go's index isn't
+			if e.settings.bigInts {
+				value = "int64(" + index + ")"
+			}
+			if len(call.children) > 4 {
+				start, err := e.rangeArgument(call.children[4], names[0].varType)
+				if err != nil {
+					return "", err
+				}
+				value += " + " + start
+			}
+			bind(names[0], value)
+		}
+		value := loopName(names[1], body)
+		if convert != "" && value != "_" {
+			value = temp()
+			bind(names[1], convert+"("+value+")")
+		}
+		return loop(index + ", " + value + " := range " + source), nil
+	case "zip":
+		index := temp()
+		captured := []string{index}
+		values := []string{"0"}
+		conditions := []string{}
+		for j := 0; j < len(names); j++ {
+			source, convert, err := e.indexSource(call.children[2+j*2])
+			if err != nil {
+				return "", err
+			}
+			if convert != "" || !isName(call.children[2+j*2]) {
+				captured = append(captured, temp())
+				values = append(values, source)
+				source = captured[len(captured)-1]
+			}
+			conditions = append(conditions, index+" < len("+source+")")
+			value := source + "[" + index + "]"
+			if convert != "" {
+				value = convert + "(" + value + ")"
+			}
+			bind(names[j], value)
+		}
+		return loop(strings.Join(captured, ", ") + " := " + strings.Join(values, ", ") + "; " + strings.Join(conditions, " && ") + "; " + index + "++"), nil
+	}
+
+	// reversed
+	if isRange(call.children[2]) {
+		header, err := e.reversedRange(call.children[2].children[0], counter(), names[0].varType, body)
+		if err != nil {
+			return "", err
+		}
+		return loop(header), nil
+	}
+	source, convert, err := e.indexSource(call.children[2])
+	if err != nil {
+		return "", err
+	}
+	output := ""
+	if convert != "" || !isName(call.children[2]) {
+		stored := temp()
+		output = "\n" + stored + " := " + source
+		source = stored
+	}
+	index := temp()
+	value := source + "[" + index + "]"
+	if convert != "" {
+		value = convert + "(" + value + ")"
+	}
+	bind(names[0], value)
+	return output + loop(index+" := len("+source+") - 1; "+index+" >= 0; "+index+"--"), nil
+}
+
+// Augmented assignments, using Go's operator where it has the same one, and
+// writing the assignment out in full where it doesn't
+func (e *Emitter) emitAugmented(ast Structure) (string, error) {
+	target, err := e.emit(ast.children[0])
+	if err != nil {
+		return "", err
+	}
+	target = strings.TrimSpace(target)
+	value, err := e.emit(ast.children[2])
+	if err != nil {
+		return "", err
+	}
+	value = strings.TrimSpace(value)
+
+	t := ast.children[0].varType
+	op := ast.children[1].text
+	base, _ := typeArguments(t)
+	divided := ast.children[1].varType == "raises"
+	if divided {
+		value = e.divisor(Operand{value, ast.children[2].varType, 3}, strings.TrimSuffix(op, "="), isFloat(t) || isFloat(ast.children[2].varType), ast.line).text
+	}
+	if base == "list" {
+		return target + " = append(" + target + ", " + value + "...)", nil
+	}
+	if e.settings.bigInts && t == "int" {
+		return target + " = " + e.bigAugmented(strings.TrimSuffix(op, "="), target, value, ast.children[2].varType), nil
+	}
+	checked := e.settings.checkedArith && fixedWidth(t, e.settings) && checkedHelpers[strings.TrimSuffix(op, "=")] != ""
+	if op == "**=" || op == "//=" || op == "%=" && (!e.settings.goSemantics || isFloat(t)) || checked {
+		// The value may be any expression, so it is bracketed where needed
+		level := 0
+		if len(ast.children[2].children) == 1 || divided {
+			level = 3
+		}
+		result := e.arithmetic(strings.TrimSuffix(op, "="), Operand{target, t, 3}, Operand{value, ast.children[2].varType, level}, t, ast.line)
+		return target + " = " + result.text, nil
+	}
+	return target + " " + op + " " + value, nil
+}
+
+// While loops. A condition that needs statements placed before it, to
+// check for an exception, is checked at the top of the loop instead, so the
+// statements run each time it is
+func (e *Emitter) emitWhile(ast Structure) (string, error) {
+	outer := e.pre
+	e.pre = []string{}
+	condition, err := e.emit(ast.children[1])
+	pre := strings.Join(e.pre, "")
+	e.pre = outer
+	if err != nil {
+		return "", err
+	}
+
+	block, after, err := e.loopBody(ast, 4)
+	if err != nil {
+		return "", err
+	}
+	if pre == "" {
+		return "for " + condition + block + after, nil
+	}
+	return "for {" + pre + "if !(" + condition + ") {\nbreak\n}\n" + block[1:] + after, nil
+}
+
+// If statements. An elif whose condition needs statements placed before it
+// becomes an else holding the statements and an if
+func (e *Emitter) emitIf(ast Structure) (string, error) {
+	output := ""
+	closing := ""
+	for i := 0; i < len(ast.children); i++ {
+		child := ast.children[i]
+		if child.code != structureCode["ST_ELIF"] {
+			temp, err := e.emit(child)
+			if err != nil {
+				return "", err
+			}
+			output += " " + temp
+			continue
+		}
+
+		outer := e.pre
+		e.pre = []string{}
+		condition, err := e.emit(child.children[1])
+		pre := strings.Join(e.pre, "")
+		e.pre = outer
+		if err != nil {
+			return "", err
+		}
+		if pre == "" {
+			output += " else if " + condition
+		} else {
+			output += " else {" + pre + "if " + condition
+			closing += "\n}"
+		}
+		for j := 2; j < len(child.children); j++ {
+			temp, err := e.emit(child.children[j])
+			if err != nil {
+				return "", err
+			}
+			output += " " + temp
+		}
+	}
+	return output + closing, nil
+}
+
+// The body of a loop, and the else clause after it. A loop with an else is
+// left with a goto past the else, rather than a break
+func (e *Emitter) loopBody(ast Structure, body int) (string, string, error) {
+	label := ""
+	if body+1 < len(ast.children) {
+		e.count++
+		label = "pyBreak" + strconv.Itoa(e.count)
+	}
+
+	e.loops = append(e.loops, label)
+	block, err := e.emit(ast.children[body])
+	e.loops = e.loops[:len(e.loops)-1]
+	if err != nil || label == "" {
+		return block, "", err
+	}
+
+	clause := ast.children[body+1]
+	after, err := e.emit(clause.children[len(clause.children)-1])
+	if err != nil {
+		return "", "", err
+	}
+	after = "\n" + after
+	if strings.Contains(block, "goto "+label+"\n") {
+		after += "\n" + label + ":\n"
+	}
+	return block, after, nil
+}
+
+// The name a value of a range loop is given, or _ if it isn't used
+func loopName(name Structure, body Structure) string {
+	if !uses(body, name.text) {
+		return "_"
+	}
+	return name.text
+}
+
+// Something enumerate, zip or reversed can index into, with the conversion
+// each item needs. Strings are indexed by rune, as Python indexes them by
+// character
+func (e *Emitter) indexSource(ast Structure) (string, string, error) {
+	source, err := e.emit(ast)
+	if err != nil {
+		return "", "", err
+	}
+	source = strings.TrimSpace(source)
+	if ast.varType == "string" {
+		return "[]rune(" + source + ")", "string", nil
+	}
+	if base, _ := typeArguments(ast.varType); base == "dict" {
+		return source + ".keys", "", nil
+	}
+	return source, "", nil
+}
+
+// The clauses of a Go for loop counting through a range. The step decides
+// which way the loop counts, so a step that isn't a literal is checked by
+// the condition
+func (e *Emitter) rangeClause(call Structure, counter string, t string, body Structure) (string, error) {
+	args := []string{}
+	for i := 2; i+1 < len(call.children); i += 2 {
+		temp, err := e.rangeArgument(call.children[i], t)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, temp)
+	}
+
+	start, stop, step := "0", args[0], "1"
+	if t != "int" {
+		start = e.goType(t) + "(0)"
+	}
+	if len(args) > 1 {
+		start, stop = args[0], args[1]
+	}
+	if len(args) > 2 {
+		step = args[2]
+	}
+
+	captured := []string{counter}
+	values := []string{start}
+	if len(args) == 1 && !fixed(call.children[2], body) || len(args) > 1 && !fixed(call.children[4], body) {
+		e.count++
+		captured = append(captured, "pyTmp"+strconv.Itoa(e.count))
+		values = append(values, stop)
+		stop = "pyTmp" + strconv.Itoa(e.count)
+	}
+	init := strings.Join(captured, ", ") + " := " + strings.Join(values, ", ")
+
+	if len(args) < 3 {
+		return init + "; " + counter + " < " + stop + "; " + counter + "++", nil
+	}
+
+	// Python reads the step once, before the loop, and raises a ValueError
+	// if it is zero
+	literal, err := strconv.Atoi(step)
+	if err != nil {
+		e.count++
+		temp := "pyTmp" + strconv.Itoa(e.count)
+		check := ""
+		if variableStep(call) {
+			e.helper("ValueError")
+			check = "if " + temp + " == 0 {" + e.throw("&ValueError{\"range() arg 3 must not be zero\"}", call.line) + "}\n"
+		}
+		e.pre = append(e.pre, "\n"+temp+" := "+step+"\n"+check)
+		step = temp
+		return init + "; (" + step + " > 0 && " + counter + " < " + stop + ") || (" + step + " < 0 && " + counter + " > " + stop + "); " + counter + " += " + step, nil
+	}
+	switch {
+	case literal == 1:
+		return init + "; " + counter + " < " + stop + "; " + counter + "++", nil
+	case literal == -1:
+		return init + "; " + counter + " > " + stop + "; " + counter + "--", nil
+	case literal < 0:
+		return init + "; " + counter + " > " + stop + "; " + counter + " -= " + strconv.Itoa(-literal), nil
+	}
+	return init + "; " + counter + " < " + stop + "; " + counter + " += " + step, nil
+}
+
+// The clauses of a Go for loop counting down through a range without a step
+func (e *Emitter) reversedRange(call Structure, counter string, t string, body Structure) (string, error) {
+	args := []string{}
+	for i := 2; i+1 < len(call.children); i += 2 {
+		temp, err := e.rangeArgument(call.children[i], t)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, temp)
+	}
+
+	if len(args) == 1 {
+		return counter + " := " + args[0] + " - 1; " + counter + " >= 0; " + counter + "--", nil
+	}
+	start := args[0]
+	init := counter + " := " + args[1] + " - 1"
+	if !fixed(call.children[2], body) {
+		e.count++
+		init = counter + ", pyTmp" + strconv.Itoa(e.count) + " := " + args[1] + " - 1, " + start
+		start = "pyTmp" + strconv.Itoa(e.count)
+	}
+	return init + "; " + counter + " >= " + start + "; " + counter + "--", nil
+}
+
+// An argument of range, converted when it is an untyped constant and the
+// range isn't of ints, so the counter gets the right type. Big ints count
+// with an int64
+func (e *Emitter) rangeArgument(ast Structure, t string) (string, error) {
+	temp, err := e.emit(ast)
+	if err != nil {
+		return "", err
+	}
+	temp = strings.TrimSpace(temp)
+	if e.settings.bigInts && ast.varType == "int" {
+		return temp + ".Int64()", nil
+	}
+	if t != "int" && ast.varType == "untyped int" {
+		return e.goType(t) + "(" + temp + ")", nil
+	}
+	return temp, nil
+}
+
+// Whether an expression can't change while a loop runs, either a literal,
+// or a variable the loop doesn't assign to
+func fixed(s Structure, body Structure) bool {
+	if len(s.children) != 1 {
+		return false
+	}
+	if isConstant(s) {
+		return true
+	}
+	return s.children[0].code == structureCode["IDENTIFIER"] && !assigns(body, s.children[0].text)
+}
+
+// Whether an expression is only a variable
+func isName(s Structure) bool {
+	return len(s.children) == 1 && s.children[0].code == structureCode["IDENTIFIER"]
+}
+
+// Whether a variable is used anywhere in a structure
+func uses(s Structure, name string) bool {
+	if s.code == structureCode["IDENTIFIER"] && s.text == name {
+		return true
+	}
+	for i := 0; i < len(s.children); i++ {
+		if uses(s.children[i], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether a variable is assigned to anywhere in a structure
+func assigns(s Structure, name string) bool {
+	if s.code == structureCode["ST_MANIPULATION"] && s.children[0].text == name {
+		return true
+	}
+	if s.code == structureCode["ST_UNPACK"] {
+		for i := 0; s.children[i].code != structureCode["ASSIGN"]; i++ {
+			if s.children[i].code == structureCode["IDENTIFIER"] && s.children[i].text == name {
+				return true
+			}
+		}
+	}
+	if s.code == structureCode["ST_FOR"] {
+		for i := 1; s.children[i].code == structureCode["IDENTIFIER"]; i += 2 {
+			if s.children[i].text == name {
+				return true
+			}
+		}
+	}
+	for i := 0; i < len(s.children); i++ {
+		if assigns(s.children[i], name) {
+			return true
+		}
+	}
+	return false
 }
 
 func (e *Emitter) emit(ast Structure) (string, error) {
@@ -15,33 +1554,147 @@ func (e *Emitter) emit(ast Structure) (string, error) {
 		return output, errors.New("[Emit (emit)] ILLEGAL structure found in final code" + " on line " + strconv.Itoa(ast.line))
 	}
 
-	// Override for loops
-	if ast.code == structureCode["ST_FOR"] {
-		identifier := ast.children[1].text
-		output += "\n" + ast.children[0].text + " " + identifier + " := " + ast.children[5].text + ";"
-		output += identifier + " < " + ast.children[7].text + ";"
-		output += identifier + "++"
-		temp, err := e.emit(ast.children[10])
+	// Imports are collected, and written in the import block
+	if ast.code == structureCode["ST_IMPORT"] {
+		return output, e.emitImport(ast)
+	}
+
+	if ast.code == structureCode["ST_CALL"] && ast.children[0].code == structureCode["IB_PRINT"] {
+		return e.emitPrint(ast)
+	}
+
+	if ast.code == structureCode["ATTRIBUTE"] {
+		return e.emitAttribute(ast)
+	}
+
+	if ast.code == structureCode["ST_FUNCTION"] && len(e.functions) > 0 {
+		return e.emitClosure(ast)
+	}
+	if ast.code == structureCode["ST_FUNCTION"] {
+		return e.emitFunction(ast, "")
+	}
+
+	// Closures already see the variables nonlocal and global name
+	if ast.code == structureCode["ST_NONLOCAL"] || ast.code == structureCode["ST_GLOBAL"] {
+		return "", nil
+	}
+
+	if ast.code == structureCode["ST_CLASS"] {
+		return e.emitClass(ast)
+	}
+
+	if ast.code == structureCode["ST_WITH"] {
+		return e.emitWith(ast)
+	}
+
+	if ast.code == structureCode["ST_DECLARATION"] {
+		temp, err := e.emit(ast.children[4])
 		if err != nil {
 			return output, err
 		}
-		output += " " + temp
-		return output, nil
+		if ast.varType == "global" {
+			return ast.children[0].text + " =" + temp, nil
+		}
+		return "var " + ast.children[0].text + " " + e.goType(ast.children[2].text) + " =" + temp, nil
 	}
 
-	if ast.code == structureCode["ST_WHILE"] {
-		output += "for "
-		temp, err := e.emit(ast.children[1])
+	if ast.code == structureCode["ST_UNPACK"] {
+		return e.emitUnpack(ast)
+	}
+
+	if ast.code == structureCode["BLOCK"] {
+		return e.emitBlock(ast)
+	}
+
+	if ast.code == structureCode["ST_CALL"] && raisingCall(ast, e.raising) {
+		return e.emitRaisingCall(ast, false)
+	}
+
+	if ast.code == structureCode["ST_CALL"] && ast.children[0].code == structureCode["ATTRIBUTE"] {
+		return e.emitMethod(ast)
+	}
+
+	if ast.code == structureCode["ST_CALL"] && ast.children[0].varType == "module" {
+		return e.emitModuleCall(ast)
+	}
+
+	if ast.code == structureCode["ST_CALL"] && ast.children[0].varType == "builtin" && isBuiltinName(ast.children[0].text) {
+		return e.emitBuiltin(ast)
+	}
+	if ast.code == structureCode["EXPRESSION"] && (len(ast.children) > 1 || e.settings.bigInts) {
+		return e.emitExpression(ast)
+	}
+	if ast.code == structureCode["COMPARISON"] && len(ast.children) == 3 && ast.children[1].varType != "" {
+		return e.emitComparison(ast)
+	}
+	if ast.code == structureCode["ST_CALL"] && ast.children[0].varType == "GoType" {
+		return e.emitConcurrency(ast)
+	}
+
+	if ast.code == structureCode["ST_CALL"] && (ast.children[0].varType == "builtin" || e.classes[ast.children[0].text].name != "") {
+		return e.emitCall(ast)
+	}
+
+	// Exceptions are pointers to their Go error type
+	if ast.code == structureCode["ST_CALL"] && exceptionParents[ast.children[0].text] != "" || ast.code == structureCode["ST_CALL"] && ast.children[0].text == "Exception" {
+		e.helper(ast.children[0].text)
+		temp, err := e.emit(ast.children[2])
 		if err != nil {
 			return output, err
 		}
-		output += temp
-		temp, err = e.emit(ast.children[4])
-		if err != nil {
-			return output, err
+		return "&" + ast.children[0].text + "{" + temp + "}", nil
+	}
+
+	if ast.code == structureCode["ST_RAISE"] {
+		return e.emitRaise(ast)
+	}
+
+	if ast.code == structureCode["ST_TRY"] {
+		return e.emitTry(ast)
+	}
+
+	if ast.code == structureCode["ST_RETURN"] && (e.functions[len(e.functions)-1].raises || e.functions[len(e.functions)-1].scoped) {
+		values := []string{}
+		for i := 1; i < len(ast.children); i += 2 {
+			temp, err := e.emit(ast.children[i])
+			if err != nil {
+				return output, err
+			}
+			values = append(values, temp)
 		}
-		output += temp
-		return output, nil
+		return e.returnStatement(values), nil
+	}
+
+	if ast.code == structureCode["ST_FOR"] {
+		return e.emitFor(ast)
+	}
+
+	if ast.code == structureCode["LIST"] || ast.code == structureCode["DICT"] {
+		return e.emitLiteral(ast)
+	}
+
+	if ast.code == structureCode["LAMBDA"] {
+		return e.emitLambda(ast)
+	}
+
+	if ast.code == structureCode["ST_WHILE"] {
+		return e.emitWhile(ast)
+	}
+
+	if ast.code == structureCode["IF_ELSE_BLOCK"] {
+		return e.emitIf(ast)
+	}
+
+	if ast.code == structureCode["ST_MANIPULATION"] && ast.children[1].code == structureCode["AUG_ASSIGN"] {
+		return e.emitAugmented(ast)
+	}
+
+	if ast.code == structureCode["ST_BREAK"] || ast.code == structureCode["ST_CONTINUE"] {
+		return e.jump(ast.code), nil
+	}
+
+	if ast.code == structureCode["ST_PASS"] {
+		return "", nil
 	}
 
 	// Own text
@@ -76,6 +1729,72 @@ func (e *Emitter) emit(ast Structure) (string, error) {
 	return output, nil
 }
 
+// The Go equivalent of a type annotation, importing any package it uses
+func (e *Emitter) goType(t string) string {
+	base, args := typeArguments(t)
+	if base == "Callable" {
+		return e.funcType(t)
+	}
+	for i := 0; i < len(args); i++ {
+		args[i] = e.goType(args[i])
+	}
+
+	switch base {
+	case "None":
+		return ""
+	case "Chan":
+		return "chan " + args[0]
+	case "WaitGroup":
+		e.require("sync")
+		return "*sync.WaitGroup"
+	case "file":
+		e.require("os")
+		return "*os.File"
+	case "tuple":
+		return "(" + strings.Join(args, ", ") + ")"
+	case "list":
+		return "[]" + args[0]
+	case "dict":
+		e.helper("pyDict")
+		return "pyDict[" + args[0] + ", " + args[1] + "]"
+	case "int":
+		if e.settings.bigInts {
+			e.require("math/big")
+			return "*big.Int"
+		}
+	}
+
+	// Objects and exceptions are pointers
+	if e.classes[t].name != "" {
+		return "*" + t
+	}
+	if _, exception := exceptionParents[t]; exception {
+		e.helper(t)
+		return "*" + t
+	}
+	return t
+}
+
+// Python modules, and what they are imported as in Go
+var goImports map[string]string = map[string]string{
+	"math":    "math",
+	"os":      "os",
+	"os.path": "path/filepath",
+	"random":  "math/rand",
+	"sys":     "os",
+	"time":    "time",
+}
+
+// Attributes of Python modules, and their Go equivalent
+var goAttributes map[string]string = map[string]string{
+	"math.e":     "math.E",
+	"math.inf":   "math.Inf(1)",
+	"math.pi":    "math.Pi",
+	"sys.argv":   "os.Args",
+	"sys.stderr": "os.Stderr",
+	"sys.stdout": "os.Stdout",
+}
+
 var translation map[int]string = map[int]string{
 	// Statements
 	structureCode["ST_DECLARATION"]: "var",
@@ -91,13 +1810,16 @@ var translation map[int]string = map[int]string{
 	structureCode["K_WHILE"]: "\nfor",
 	structureCode["K_DEF"]:   "\nfunc",
 
-	// In-built functions
-	structureCode["IB_PRINT"]: "println",
+	// Literal
+	structureCode["L_NULL"]: "nil",
 
 	// Bool operands
 	structureCode["BO_NOT"]: "!",
 	structureCode["BO_AND"]: "&&",
 	structureCode["BO_OR"]:  "||",
+
+	// Math operands
+	structureCode["MO_FLOOR_DIV"]: "/",
 }
 
 var directs []int = []int{
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"strconv"
+	"strings"
 )
 
 func createError(funcLine []string, message string, line int) error {
@@ -15,3 +16,18 @@ func createError(funcLine []string, message string, line int) error {
 	output += message
 	return errors.New(output)
 }
+
+// Says which file an error is in, before its line, as each module of a
+// program counts its lines from its own start
+func fileError(err error, file string) error {
+	output := err.Error()
+	switch {
+	case strings.Contains(output, ".py -> line: "), strings.Contains(output, ".py on line "):
+		return err
+	case strings.Contains(output, "line: "):
+		return errors.New(strings.Replace(output, "line: ", file+" -> line: ", 1))
+	case strings.Contains(output, " on line "):
+		return errors.New(strings.Replace(output, " on line ", " in "+file+" on line ", 1))
+	}
+	return errors.New(file + ": " + output)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"go/constant"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// Go packages are imported with import go.net.url as url, or with
+// from GoType.go import strings. Their API is read from the source in GOROOT,
+// and their functions, constants and variables are used directly
+
+func isGoModule(name string) bool {
+	return name == "go" || strings.HasPrefix(name, "go.")
+}
+
+// The import path of a Go package, such as net/url for go.net.url
+func goPath(module string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(module, "go."), ".", "/")
+}
+
+// Splits the full name of something in a Go package, such as
+// go.net.url.QueryEscape, into the package path and the name
+func goMember(name string) (string, string) {
+	last := strings.LastIndex(name, ".")
+	return goPath(name[:last]), name[last+1:]
+}
+
+func (a *Analyzer) loadPackage(path string, line int) error {
+	if a.packages == nil {
+		a.packages = map[string]*types.Package{}
+	}
+	if _, exists := a.packages[path]; exists {
+		return nil
+	}
+	pkg, err := importer.ForCompiler(token.NewFileSet(), "source", nil).Import(path)
+	if err != nil {
+		return createError([]string{"gopackages.go", "loadPackage"}, "The Go package \""+path+"\" couldn't be read: "+err.Error(), line)
+	}
+	a.packages[path] = pkg
+	return nil
+}
+
+// What a name in an imported Go package refers to
+func (a *Analyzer) goObject(name string, line int) (types.Object, error) {
+	path, member := goMember(name)
+	err := a.loadPackage(path, line)
+	if err != nil {
+		return nil, err
+	}
+	object := a.packages[path].Scope().Lookup(member)
+	if object == nil || !object.Exported() {
+		return nil, createError([]string{"gopackages.go", "goObject"}, "\""+name+"\" doesn't exist", line)
+	}
+	return object, nil
+}
+
+// A Go type as the Pogo type it is used as, and whether there is one. Under
+// -big-ints only a lone int can be converted, not one inside a container. A
+// map is converted to a dict the same way, so it can't be inside a container,
+// and its keys must be ordered to put them in a stable order
+func pogoType(t types.Type, bigInts bool) (string, bool) {
+	switch t := t.(type) {
+	case *types.Basic:
+		switch t.Kind() {
+		case types.UntypedInt, types.UntypedRune:
+			return "untyped int", true
+		case types.UntypedFloat:
+			return "untyped float", true
+		case types.UntypedString:
+			return "string", true
+		case types.UntypedBool:
+			return "bool", true
+		}
+		if t.Name() == "string" || t.Name() == "bool" || isNumeric(t.Name()) {
+			return t.Name(), true
+		}
+	case *types.Slice:
+		item, valid := pogoType(t.Elem(), bigInts)
+		if valid && !(bigInts && item == "int") && !strings.HasPrefix(item, "dict") {
+			return "list[" + item + "]", true
+		}
+	case *types.Map:
+		key, valid := pogoType(t.Key(), bigInts)
+		value, validValue := pogoType(t.Elem(), bigInts)
+		if valid && validValue && isOrdered(key) && !strings.HasPrefix(value, "dict") && !(bigInts && (key == "int" || value == "int")) {
+			return "dict[" + key + ", " + value + "]", true
+		}
+	case *types.Interface:
+		if t.Empty() {
+			return "any", true
+		}
+	}
+	return "", false
+}
+
+// The signature of a Go function in Pogo types. A last error result is left
+// out of the type, and raised when it isn't nil
+func goFunction(object types.Object, bigInts bool) (Function, bool) {
+	function, valid := object.(*types.Func)
+	if !valid {
+		return Function{}, false
+	}
+	signature := function.Type().(*types.Signature)
+	if signature.TypeParams().Len() > 0 {
+		return Function{}, false
+	}
+
+	fn := Function{name: function.Name(), variadic: signature.Variadic()}
+	for i := 0; i < signature.Params().Len(); i++ {
+		param := signature.Params().At(i).Type()
+		if fn.variadic && i == signature.Params().Len()-1 {
+			param = param.(*types.Slice).Elem()
+		}
+		t, valid := pogoType(param, bigInts)
+		if !valid {
+			return fn, false
+		}
+		fn.params = append(fn.params, t)
+	}
+
+	results := []string{}
+	for i := 0; i < signature.Results().Len(); i++ {
+		result := signature.Results().At(i).Type()
+		if i == signature.Results().Len()-1 && types.Identical(result, types.Universe.Lookup("error").Type()) {
+			fn.errors = true
+			continue
+		}
+		t, valid := pogoType(result, bigInts)
+		if !valid {
+			return fn, false
+		}
+		results = append(results, t)
+	}
+
+	switch len(results) {
+	case 0:
+		fn.varType = "None"
+	case 1:
+		fn.varType = results[0]
+	default:
+		fn.varType = "tuple[" + strings.Join(results, ", ") + "]"
+	}
+	return fn, true
+}
+
+// The type of a Go constant or variable. Untyped constants are given the type
+// Python would give them, as there is no literal to check
+func goValue(object types.Object, bigInts bool) (string, bool) {
+	switch object.(type) {
+	case *types.Const, *types.Var:
+		t, valid := pogoType(object.Type(), bigInts)
+		return defaultType(t), valid
+	}
+	return "", false
+}
+
+// Calls to Go functions, with arguments checked against the signature
+func (a *Analyzer) goCallType(s Structure, name string, vars []Variable, funcs []Function) (string, error) {
+	object, err := a.goObject(name, s.line)
+	if err != nil {
+		return "", err
+	}
+	fn, valid := goFunction(object, a.settings.bigInts)
+	if !valid {
+		return "", createError([]string{"gopackages.go", "goCallType"}, "\""+name+"\" can't be called from Pogo, it isn't a function of types Pogo has", s.line)
+	}
+	return a.checkModuleCall(s, name, fn, vars, funcs)
+}
+
+// Constants and variables of Go packages
+func (a *Analyzer) goValueType(s Structure, name string) (string, error) {
+	object, err := a.goObject(name, s.line)
+	if err != nil {
+		return "", err
+	}
+	if value, isConstant := object.(*types.Const); isConstant && value.Val().Kind() == constant.Int {
+		if _, exact := constant.Int64Val(value.Val()); !exact {
+			return "", createError([]string{"gopackages.go", "goValueType"}, "\""+name+"\" is too big for an int", s.line)
+		}
+	}
+	if t, valid := goValue(object, a.settings.bigInts); valid {
+		return t, nil
+	}
+	return "", createError([]string{"gopackages.go", "goValueType"}, "\""+name+"\" can't be used from Pogo, it isn't a value of a type Pogo has", s.line)
+}
+
+// Finds the calls to Go functions that return an error, which raise it as an
+// Exception. They are found before the program is analyzed, with the imports
+// it makes, so the functions that call them are known to raise. Imports that
+// can't be read are reported when they are analyzed
+func (a *Analyzer) goRaising(program Structure, raising map[string]bool) {
+	for i := 0; i < len(program.children); i++ {
+		if program.children[i].code == structureCode["ST_IMPORT"] {
+			a.importModule(program.children[i])
+		}
+	}
+	a.findGoRaising(program, raising)
+}
+
+func (a *Analyzer) findGoRaising(s Structure, raising map[string]bool) {
+	if s.code == structureCode["ST_CALL"] && s.children[0].code == structureCode["ATTRIBUTE"] {
+		if module := moduleName(s.children[0], a.modules); isGoModule(module) {
+			object, err := a.goObject(module, s.line)
+			if err == nil {
+				if fn, valid := goFunction(object, a.settings.bigInts); valid && fn.errors {
+					raising[callName(s)] = true
+				}
+			}
+		}
+	}
+	for i := 0; i < len(s.children); i++ {
+		a.findGoRaising(s.children[i], raising)
+	}
+}
+
+// A call to a Go function. One that returns an error gives it as an
+// Exception, after its results, as functions that raise do
+func (e *Emitter) emitGoCall(ast Structure, name string) (string, error) {
+	path, member := goMember(name)
+	pkg := e.packages[path]
+	fn, _ := goFunction(pkg.Scope().Lookup(member), e.settings.bigInts)
+
+	args, err := e.moduleArguments(ast, fn)
+	if err != nil {
+		return "", err
+	}
+	e.require(path)
+	call := pkg.Name() + "." + member + "(" + strings.Join(args, ", ") + ")"
+
+	results := []string{}
+	if fn.varType != "None" {
+		results = []string{fn.varType}
+	}
+	if isTuple(fn.varType) {
+		_, results = typeArguments(fn.varType)
+	}
+
+	// Results that are converted, or checked for an error, are taken apart
+	// in a function literal
+	converted := false
+	for i := 0; i < len(results); i++ {
+		converted = converted || e.fromGo("r", results[i]) != "r"
+	}
+	if !fn.errors && (!converted || len(results) == 1) {
+		return e.fromGo(call, fn.varType), nil
+	}
+
+	names := []string{}
+	returns := []string{}
+	kinds := []string{}
+	for i := 0; i < len(results); i++ {
+		names = append(names, "r"+strconv.Itoa(i))
+		returns = append(returns, e.fromGo(names[i], results[i]))
+		kinds = append(kinds, e.goType(results[i]))
+	}
+	if fn.errors {
+		names = append(names, "err")
+		returns = append(returns, "err")
+		kinds = append(kinds, "error")
+	}
+
+	signature := strings.Join(kinds, ", ")
+	if len(kinds) > 1 {
+		signature = "(" + signature + ")"
+	}
+	body := strings.Join(names, ", ") + " := " + call + "\n"
+	if fn.errors {
+		e.helper("Exception")
+		body += "if err != nil {\nerr = &Exception{err.Error()}\n}\n"
+	}
+	return "func() " + signature + " {\n" + body + "return " + strings.Join(returns, ", ") + "\n}()", nil
+}
+
+// Constants and variables of Go packages
+func (e *Emitter) emitGoValue(name string) string {
+	path, member := goMember(name)
+	e.require(path)
+	pkg := e.packages[path]
+	t, _ := goValue(pkg.Scope().Lookup(member), e.settings.bigInts)
+	return e.fromGo(pkg.Name()+"."+member, t)
+}
+
+// A value from Go as the Pogo value of type t, converting ints under
+// -big-ints, and maps to dicts
+func (e *Emitter) fromGo(text string, t string) string {
+	if base, _ := typeArguments(t); base == "dict" {
+		e.helper("pyFromMap")
+		return "pyFromMap(" + text + ")"
+	}
+	if t == "int" {
+		return e.pythonInt(text)
+	}
+	return text
+}
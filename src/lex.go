@@ -1,7 +1,7 @@
 package main
 
 import (
-	"log"
+	"errors"
 	"strconv"
 	"unicode"
 )
@@ -39,9 +39,23 @@ func (l *Lexer) peek() byte {
 	return l.source[l.curPos+1]
 }
 
-func (l *Lexer) lex(input []byte) []Token {
+// Operators that can be used in an augmented assignment, longest first
+var augmentedOperators []string = []string{"**", "//", "<<", ">>", "+", "-", "*", "/", "%", "|", "&", "^"}
+
+// The operator of the augmented assignment the lexer is at, if it is at one
+func (l *Lexer) augmented() string {
+	for i := 0; i < len(augmentedOperators); i++ {
+		end := l.curPos + len(augmentedOperators[i]) + 1
+		if end <= len(l.source) && string(l.source[l.curPos:end]) == augmentedOperators[i]+"=" {
+			return augmentedOperators[i]
+		}
+	}
+	return ""
+}
+
+func (l *Lexer) lex(input []byte) ([]Token, error) {
 	if len(input) == 0 {
-		log.Fatal("[Lex (lex)] Missing input")
+		return nil, errors.New("[Lex (lex)] Missing input")
 	}
 
 	l.source = input
@@ -54,6 +68,17 @@ func (l *Lexer) lex(input []byte) []Token {
 	for l.curPos < len(l.source) {
 		var token Token
 
+		// Augmented assignments, such as +=
+		op := l.augmented()
+		if op != "" {
+			for i := 0; i < len(op); i++ {
+				l.nextChar()
+			}
+			tokens = append(tokens, Token{tokenCode["AUG_ASSIGN"], op + "=", l.line})
+			l.nextCharNoWhiteSpace()
+			continue
+		}
+
 		// Math Operands
 		if l.curChar == '+' {
 			token = Token{tokenCode["MO_PLUS"], "+", l.line}
@@ -67,9 +92,19 @@ func (l *Lexer) lex(input []byte) []Token {
 				token = Token{tokenCode["MO_SUB"], "-", l.line}
 			}
 		} else if l.curChar == '*' {
-			token = Token{tokenCode["UNDETERMINED"], "*", l.line} // Could be for import
+			if l.peek() == '*' {
+				l.nextChar()
+				token = Token{tokenCode["MO_POW"], "**", l.line}
+			} else {
+				token = Token{tokenCode["MO_MUL"], "*", l.line} // Could be for import
+			}
 		} else if l.curChar == '/' {
-			token = Token{tokenCode["MO_DIV"], "/", l.line}
+			if l.peek() == '/' {
+				l.nextChar()
+				token = Token{tokenCode["MO_FLOOR_DIV"], "//", l.line}
+			} else {
+				token = Token{tokenCode["MO_DIV"], "/", l.line}
+			}
 		} else if l.curChar == '%' {
 			token = Token{tokenCode["MO_MODULO"], "%", l.line}
 		}
@@ -80,13 +115,13 @@ func (l *Lexer) lex(input []byte) []Token {
 		} else if l.curChar == ')' {
 			token = Token{tokenCode["R_PAREN"], ")", l.line}
 		} else if l.curChar == '[' {
-			token = Token{tokenCode["L_BLOCK"], ")", l.line}
+			token = Token{tokenCode["L_BLOCK"], "[", l.line}
 		} else if l.curChar == ']' {
-			token = Token{tokenCode["R_BLOCK"], ")", l.line}
+			token = Token{tokenCode["R_BLOCK"], "]", l.line}
 		} else if l.curChar == '{' {
-			token = Token{tokenCode["L_SQUIRLY"], ")", l.line}
+			token = Token{tokenCode["L_SQUIRLY"], "{", l.line}
 		} else if l.curChar == '}' {
-			token = Token{tokenCode["R_SQUIRLY"], ")", l.line}
+			token = Token{tokenCode["R_SQUIRLY"], "}", l.line}
 		}
 
 		// Other
@@ -98,6 +133,8 @@ func (l *Lexer) lex(input []byte) []Token {
 			token = Token{tokenCode["SEP"], ",", l.line}
 		} else if l.curChar == ':' {
 			token = Token{tokenCode["COLON"], ":", l.line}
+		} else if l.curChar == '.' {
+			token = Token{tokenCode["ACCESSOR"], ".", l.line}
 		} else if l.curChar == '#' {
 			start := l.curPos
 			for l.peek() != '\r' && l.peek() != '\n' {
@@ -156,9 +193,9 @@ func (l *Lexer) lex(input []byte) []Token {
 		}
 
 		// Words
-		if unicode.IsLetter(rune(l.curChar)) {
+		if unicode.IsLetter(rune(l.curChar)) || l.curChar == '_' {
 			start := l.curPos
-			for unicode.IsLetter(rune(l.peek())) {
+			for unicode.IsLetter(rune(l.peek())) || unicode.IsDigit(rune(l.peek())) || l.peek() == '_' {
 				l.nextChar()
 			}
 			word := string(l.source[start : l.curPos+1])
@@ -184,6 +221,30 @@ func (l *Lexer) lex(input []byte) []Token {
 				token = Token{tokenCode["K_DEF"], word, l.line}
 			} else if word == "return" {
 				token = Token{tokenCode["K_RETURN"], word, l.line}
+			} else if word == "raise" {
+				token = Token{tokenCode["K_RAISE"], word, l.line}
+			} else if word == "try" {
+				token = Token{tokenCode["K_TRY"], word, l.line}
+			} else if word == "except" {
+				token = Token{tokenCode["K_EXCEPT"], word, l.line}
+			} else if word == "as" {
+				token = Token{tokenCode["K_AS"], word, l.line}
+			} else if word == "with" {
+				token = Token{tokenCode["K_WITH"], word, l.line}
+			} else if word == "class" {
+				token = Token{tokenCode["K_CLASS"], word, l.line}
+			} else if word == "break" {
+				token = Token{tokenCode["K_BREAK"], word, l.line}
+			} else if word == "continue" {
+				token = Token{tokenCode["K_CONTINUE"], word, l.line}
+			} else if word == "pass" {
+				token = Token{tokenCode["K_PASS"], word, l.line}
+			} else if word == "lambda" {
+				token = Token{tokenCode["K_LAMBDA"], word, l.line}
+			} else if word == "nonlocal" {
+				token = Token{tokenCode["K_NONLOCAL"], word, l.line}
+			} else if word == "global" {
+				token = Token{tokenCode["K_GLOBAL"], word, l.line}
 			}
 
 			// In-Built Funcs
@@ -230,7 +291,7 @@ func (l *Lexer) lex(input []byte) []Token {
 		}
 
 		// Integer literal
-		if unicode.IsDigit(rune(l.curChar)) {
+		if token == (Token{}) && unicode.IsDigit(rune(l.curChar)) {
 			start := l.curPos
 			for unicode.IsDigit(rune(l.peek())) || l.peek() == '_' || l.peek() == '.' {
 				l.nextChar()
@@ -238,8 +299,7 @@ func (l *Lexer) lex(input []byte) []Token {
 			num := string(l.source[start : l.curPos+1])
 
 			if num[len(num)-1] == '_' || num[len(num)-1] == '.' {
-				log.Fatal("[Lex (lex)] Numbers must end with a digit on line " + strconv.Itoa(l.line))
-				return tokens
+				return tokens, errors.New("[Lex (lex)] Numbers must end with a digit on line " + strconv.Itoa(l.line))
 			}
 
 			dot_index := -1
@@ -249,7 +309,7 @@ func (l *Lexer) lex(input []byte) []Token {
 				if num[i] == '.' {
 					dot_index = i
 					if has_dot {
-						log.Fatal("[Lex (lex)] Numbers can only have one dot on line " + strconv.Itoa(l.line))
+						return tokens, errors.New("[Lex (lex)] Numbers can only have one dot on line " + strconv.Itoa(l.line))
 					}
 					has_dot = true
 				}
@@ -257,7 +317,7 @@ func (l *Lexer) lex(input []byte) []Token {
 
 			if dot_index != -1 {
 				if num[dot_index-1] == '_' || num[dot_index+1] == '_' {
-					log.Fatal("[Lex (lex)] Cannot place underscores next to dots in numbers on " + strconv.Itoa(l.line))
+					return tokens, errors.New("[Lex (lex)] Cannot place underscores next to dots in numbers on " + strconv.Itoa(l.line))
 				}
 			}
 
@@ -274,5 +334,5 @@ func (l *Lexer) lex(input []byte) []Token {
 		l.nextCharNoWhiteSpace()
 	}
 
-	return tokens
+	return tokens, nil
 }
@@ -1,26 +1,64 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 )
 
+// Options that change the code that is emitted
+type Settings struct {
+	goSemantics  bool // Use Go's arithmetic rather than Python's, for speed
+	bigInts      bool // Make int a *big.Int, so it never overflows like Python's
+	checkedArith bool // Panic when arithmetic on a fixed width int overflows
+}
+
 func main() {
-	args := os.Args
-	if len(args) == 2 {
-		compile_file(args[1])
+	settings := Settings{}
+	flag.BoolVar(&settings.goSemantics, "go-semantics", false, "use Go's rules for /, //, % and mixed int and float arithmetic")
+	flag.BoolVar(&settings.bigInts, "big-ints", false, "make int a *big.Int from math/big, which never overflows")
+	flag.BoolVar(&settings.checkedArith, "checked-arith", false, "panic with the Python line when arithmetic on a fixed width int overflows")
+	run := flag.Bool("run", false, "with watch, run the program after each build that works")
+	flag.Parse()
+
+	// pogo build dir transpiles a project, and pogo watch dir transpiles it
+	// whenever it changes. They take the flags after them too
+	args := flag.Args()
+	if len(args) > 0 && (args[0] == "build" || args[0] == "watch") {
+		command := args[0]
+		flag.CommandLine.Parse(args[1:])
+		args = flag.Args()
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if command == "watch" {
+			watchProject(dir, settings, *run)
+			return
+		}
+		_, err := buildProject(dir, settings, true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) == 1 {
+		compile_file(args[0], settings)
 	}
 }
 
-func compile_file(fileName string) {
-	readFile, err := os.ReadFile(fileName + ".py")
+func compile_file(fileName string, settings Settings) {
+	modules, err := loadProgram(fileName)
 	if err != nil {
-		log.Fatal("[main (compile_file)] File error")
-		return
+		log.Fatal(err)
 	}
+	printTrees(modules)
 
-	output := compile(readFile)
+	output, err := compile(modules, settings)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Write to the file and close it
 	f, err := os.Create("../Output/" + fileName + ".go")
@@ -39,57 +77,147 @@ func compile_file(fileName string) {
 	}
 }
 
-func compile(input []byte) string {
-	//fmt.Println(input)
-
-	// Lex
-	lexer := Lexer{}
-	lexSource := lexer.lex(input)
-	//fmt.Println(lexSource)
-
-	// Parse
-	parser := Parser{}
-	pS := parser.replaceIndents(lexSource)
-	//fmt.Println(pS)
-	ast := parser.parse(pS)
-	//fmt.Println(ast)
-	fmt.Println(ast.stringify())
-
-	main_func := Structure{
-		structureCode["ST_FUNCTION"],
-		"ST_FUNCTION",
-		-1,
-		[]Structure{
-			createStructure("K_DEF", "def", -1),
-			createStructure("FUNC_NAME", "main", -1),
-			createStructure("L_PAREN", "(", -1),
-			createStructure("R_PAREN", ")", -1),
-			createStructure("COLON", ":", -1),
-			{structureCode["BLOCK"], "", -1, append(ast.children, createStructure("ANTI_COLON", ":", -1))},
-		},
-	}
-
-	ast.children = []Structure{main_func}
-	ast.children = append(parser.functions, ast.children...)
+// Analyzes the modules of a program, the entry file last, as one Go package.
+// The indexes of each module's top level structures are kept, so they can be
+// emitted into a file of their own. Clean modules aren't analyzed, but what
+// they declare comes from the cache
+func analyzeProgram(modules []Module, settings Settings) (Structure, [][]int, Analyzer, error) {
+	// Built in functions and division raise depending on the types of their
+	// arguments, which are only known once they are typed. Functions found
+	// to raise that way are given as raising, and the modules are parsed and
+	// analyzed again, so every check sees them raise
+	raises := map[string]bool{}
+	for {
+		ast, files, analyzer, err := analyzeModules(modules, settings, raises)
+		if err != nil {
+			return ast, files, analyzer, err
+		}
+		found := false
+		for name := range analyzer.raisingFunctions(ast, analyzer.raising) {
+			if !analyzer.raising[name] {
+				raises[name] = true
+				found = true
+			}
+		}
+		if !found {
+			return ast, files, analyzer, nil
+		}
+		err = reload(modules)
+		if err != nil {
+			return ast, files, analyzer, err
+		}
+	}
+}
 
+// Analyzes the modules once, with the functions in raises known to raise
+func analyzeModules(modules []Module, settings Settings, raises map[string]bool) (Structure, [][]int, Analyzer, error) {
+	analyzer := Analyzer{settings: settings}
+	funcs := append(exceptionFunctions(), openFunction(), Function{name: "print", params: []string{"any"}, varType: "None", variadic: true})
+	known := map[string]bool{}
+	for name := range raises {
+		known[name] = true
+	}
+
+	// Imports are kept outside main, so the functions before it can use them
+	imports := []Structure{}
+	functions := []Structure{}
+	files := make([][]int, len(modules))
+	for i := 0; i < len(modules); i++ {
+		if modules[i].clean {
+			continue
+		}
+		for j := 0; j < len(modules[i].imports); j++ {
+			files[i] = append(files[i], len(imports))
+			imports = append(imports, modules[i].imports[j])
+		}
+	}
+	vars := []Variable{}
+	for i := 0; i < len(modules); i++ {
+		global := modules[i].packageVariables()
+		for j := 0; j < len(global); j++ {
+			vars = append(vars, global[j])
+			if isCallable(global[j].varType) {
+				funcs = append(funcs, callableFunction(global[j].name, global[j].varType))
+			}
+		}
+		if modules[i].clean {
+			err := modules[i].declareCached(&analyzer, &funcs, known)
+			if err != nil {
+				return Structure{}, nil, analyzer, fileError(err, modules[i].file())
+			}
+			continue
+		}
+		err := analyzer.declareGlobals(modules[i])
+		if err != nil {
+			return Structure{}, nil, analyzer, fileError(err, modules[i].file())
+		}
+		modules[i].signatures = []CachedStructure{}
+		for j := 0; j < len(modules[i].functions); j++ {
+			files[i] = append(files[i], len(imports)+len(functions))
+			functions = append(functions, modules[i].functions[j])
+			modules[i].signatures = append(modules[i].signatures, signatureOf(modules[i].functions[j]))
+		}
+	}
+
+	// The top level code of imported modules runs first, as Python runs it on
+	// import
+	inits := []Structure{}
+	for i := 0; i < len(modules)-1; i++ {
+		if !modules[i].runs() {
+			continue
+		}
+		name := initName(modules[i].name)
+		inits = append(inits, initCall(name), createStructure("NEWLINE", "NEWLINE", -1))
+		if !modules[i].clean {
+			files[i] = append(files[i], len(imports)+len(functions))
+			functions = append(functions, topLevelFunction(name, modules[i].body))
+		}
+	}
+	if entry := len(modules) - 1; !modules[entry].clean {
+		files[entry] = append(files[entry], len(imports)+len(functions))
+		functions = append(functions, topLevelFunction("main", append(inits, modules[entry].body...)))
+	}
+
+	ast := Structure{structureCode["PROGRAM"], "PROGRAM", 0, append(imports, functions...), ""}
 	//fmt.Println(ast.stringify())
 
 	// Analyze
-	analyzer := Analyzer{}
-	err := analyzer.analyze(ast, []Variable{}, []Function{{"print", []string{"any"}, "None"}})
+	analyzer.raising = analyzer.raisingFunctions(ast, known)
+	err := analyzer.analyze(ast, vars, funcs)
 	if err != nil {
-		log.Fatal(err)
+		return ast, files, analyzer, fileError(err, fileAt(modules, files, analyzer.top))
+	}
+	return ast, files, analyzer, nil
+}
+
+// The file the top level structure at index i of the program comes from
+func fileAt(modules []Module, files [][]int, i int) string {
+	for j := 0; j < len(files); j++ {
+		for k := 0; k < len(files[j]); k++ {
+			if files[j][k] == i {
+				return modules[j].file()
+			}
+		}
+	}
+	return modules[len(modules)-1].file()
+}
+
+// Compiles the modules of a program into one Go file
+func compile(modules []Module, settings Settings) (string, error) {
+	ast, _, analyzer, err := analyzeProgram(modules, settings)
+	if err != nil {
+		return "", err
 	}
 
 	// Optimize
 
 	// Emit
-	emitter := Emitter{}
+	emitter := Emitter{raising: analyzer.raising, classes: analyzer.classes, packages: analyzer.packages, settings: settings}
 	emitSource, err := emitter.emit(ast)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	// Final code
 	//fmt.Println(emitSource)
-	return "package main\n" + emitSource
+	return "package main\n" + importBlock(append(emitter.imports, emitter.helperImports()...)) + emitSource + "\n" + emitter.helperSource(), nil
 }
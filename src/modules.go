@@ -0,0 +1,289 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Functions of the Python modules that have a Go equivalent. float64
+// parameters take any number, as Python's math does, and T stands in for the
+// type of a list's items
+var moduleFunctions map[string]Function = map[string]Function{
+	"math.atan2":        {params: []string{"float64", "float64"}, varType: "float64"},
+	"math.ceil":         {params: []string{"float64"}, varType: "int"},
+	"math.cos":          {params: []string{"float64"}, varType: "float64"},
+	"math.exp":          {params: []string{"float64"}, varType: "float64"},
+	"math.fabs":         {params: []string{"float64"}, varType: "float64"},
+	"math.floor":        {params: []string{"float64"}, varType: "int"},
+	"math.hypot":        {params: []string{"float64", "float64"}, varType: "float64"},
+	"math.isinf":        {params: []string{"float64"}, varType: "bool"},
+	"math.isnan":        {params: []string{"float64"}, varType: "bool"},
+	"math.log":          {params: []string{"float64"}, varType: "float64"},
+	"math.log10":        {params: []string{"float64"}, varType: "float64"},
+	"math.log2":         {params: []string{"float64"}, varType: "float64"},
+	"math.pow":          {params: []string{"float64", "float64"}, varType: "float64"},
+	"math.sin":          {params: []string{"float64"}, varType: "float64"},
+	"math.sqrt":         {params: []string{"float64"}, varType: "float64"},
+	"math.tan":          {params: []string{"float64"}, varType: "float64"},
+	"os.path.basename":  {params: []string{"string"}, varType: "string"},
+	"os.path.dirname":   {params: []string{"string"}, varType: "string"},
+	"os.path.exists":    {params: []string{"string"}, varType: "bool"},
+	"os.path.join":      {params: []string{"string"}, varType: "string", variadic: true},
+	"random.choice":     {params: []string{"list[T]"}, varType: "T"},
+	"random.randint":    {params: []string{"int", "int"}, varType: "int"},
+	"random.random":     {params: []string{}, varType: "float64"},
+	"random.seed":       {params: []string{"int"}, varType: "None"},
+	"random.shuffle":    {params: []string{"list[T]"}, varType: "None"},
+	"random.uniform":    {params: []string{"float64", "float64"}, varType: "float64"},
+	"sys.exit":          {params: []string{"int"}, varType: "None", optional: 1},
+	"time.perf_counter": {params: []string{}, varType: "float64"},
+	"time.sleep":        {params: []string{"float64"}, varType: "None"},
+	"time.time":         {params: []string{}, varType: "float64"},
+}
+
+// The module an import brings in, and the name it is used with. Importing a
+// dotted name without as binds its first part, as Python does
+func importNames(s Structure) (string, string) {
+	name := s.children[1].text
+	alias := name
+	switch {
+	case s.children[0].code == structureCode["K_FROM"]:
+		name = strings.TrimPrefix(name, "GoType.") + "." + s.children[3].text
+		alias = s.children[3].text
+	case len(s.children) == 4:
+		alias = s.children[3].text
+	case strings.Contains(name, "."):
+		alias = name[:strings.Index(name, ".")]
+		name = alias
+	}
+	return name, alias
+}
+
+// Keeps the name an imported module is used with, and reads the API of Go
+// packages. Other modules without a Go equivalent are reported by the emitter
+func (a *Analyzer) importModule(s Structure) error {
+	if a.modules == nil {
+		a.modules = map[string]string{}
+	}
+	name, alias := importNames(s)
+	if s.children[0].code == structureCode["K_FROM"] && s.children[3].code == structureCode["ASTERISK"] {
+		return nil
+	}
+	a.modules[alias] = name
+
+	// Only the functions of a Python module can be used without its name
+	module := strings.TrimPrefix(s.children[1].text, "GoType.")
+	_, python := goImports[module]
+	_, submodule := goImports[name]
+	if _, function := moduleFunctions[name]; s.children[0].code == structureCode["K_FROM"] && python && !submodule && !function {
+		return createError([]string{"modules.go", "importModule"}, "\""+alias+"\" can't be imported from "+module+", as only its functions and modules can. Import "+module+" and use "+name, s.line)
+	}
+
+	if s.children[0].code == structureCode["K_FROM"] {
+		module = name
+	}
+	if isGoModule(module) && module != "go" {
+		return a.loadPackage(goPath(module), s.line)
+	}
+	return nil
+}
+
+// The full name of what an ATTRIBUTE refers to in a module, such as
+// os.path.join, or "" if it doesn't start with a module. A function imported
+// with from is called by its name alone, so sqrt can refer to math.sqrt
+func moduleName(callee Structure, modules map[string]string) string {
+	if callee.code == structureCode["FUNC_NAME"] {
+		if _, exists := moduleFunctions[modules[callee.text]]; exists {
+			return modules[callee.text]
+		}
+		return ""
+	}
+	module, imported := modules[callee.children[0].text]
+	if callee.children[0].code != structureCode["IDENTIFIER"] || !imported {
+		return ""
+	}
+	for i := 1; i < len(callee.children); i++ {
+		module += callee.children[i].text
+	}
+	return module
+}
+
+// The module function a call is to, unless a variable hides the module, or
+// a function of the program hides a function imported with from
+func (a *Analyzer) moduleFunction(callee Structure, vars []Variable, funcs []Function) string {
+	if callee.code == structureCode["FUNC_NAME"] {
+		_, variable := findVariable(callee.text, vars)
+		_, defined := findFunction(callee.text, funcs)
+		if variable || defined {
+			return ""
+		}
+		return moduleName(callee, a.modules)
+	}
+	if callee.code != structureCode["ATTRIBUTE"] {
+		return ""
+	}
+	if _, variable := findVariable(callee.children[0].text, vars); variable {
+		return ""
+	}
+	return moduleName(callee, a.modules)
+}
+
+func (a *Analyzer) moduleCallType(s Structure, name string, vars []Variable, funcs []Function) (string, error) {
+	if isGoModule(name) {
+		return a.goCallType(s, name, vars, funcs)
+	}
+	fn, exists := moduleFunctions[name]
+	if !exists {
+		return "", createError([]string{"modules.go", "moduleCallType"}, "\""+name+"\" doesn't exist", s.line)
+	}
+	return a.checkModuleCall(s, name, fn, vars, funcs)
+}
+
+// The arguments of a call to a module function, checked against its
+// parameters, and the type it returns
+func (a *Analyzer) checkModuleCall(s Structure, name string, fn Function, vars []Variable, funcs []Function) (string, error) {
+	args := (len(s.children) - 2) / 2
+	if args > len(fn.params) && !fn.variadic || args < len(fn.params)-fn.optional {
+		return "", createError([]string{"modules.go", "checkModuleCall"}, "\""+name+"\" takes "+strconv.Itoa(len(fn.params))+" arguments, got "+strconv.Itoa(args), s.line)
+	}
+
+	item := ""
+	for i := 0; i < args; i++ {
+		if s.children[2+i*2].code == structureCode["KEYWORD_ARG"] {
+			return "", createError([]string{"modules.go", "checkModuleCall"}, "\""+name+"\" doesn't take keyword arguments", s.line)
+		}
+		want := fn.params[len(fn.params)-1]
+		if i < len(fn.params) {
+			want = fn.params[i]
+		}
+		t, err := a.typeChild(s, 2+i*2, vars, funcs)
+		if err != nil {
+			return "", err
+		}
+
+		base, items := typeArguments(t)
+		switch {
+		case want == "float64" && (isIntegral(t) || isFloat(t)):
+		case want == "list[T]" && base == "list":
+			item = items[0]
+		case !assignable(want, t):
+			return "", createError([]string{"modules.go", "checkModuleCall"}, "Excpected "+want+" got "+t+" in call to \""+name+"\"", s.line)
+		}
+	}
+
+	if fn.varType == "T" {
+		return item, nil
+	}
+	return fn.varType, nil
+}
+
+// Module functions, and the name of their Go equivalent in the package the
+// module is imported as
+var goFunctions map[string]string = map[string]string{
+	"math.atan2":       "Atan2",
+	"math.cos":         "Cos",
+	"math.exp":         "Exp",
+	"math.fabs":        "Abs",
+	"math.hypot":       "Hypot",
+	"math.isnan":       "IsNaN",
+	"math.log":         "Log",
+	"math.log10":       "Log10",
+	"math.log2":        "Log2",
+	"math.pow":         "Pow",
+	"math.sin":         "Sin",
+	"math.sqrt":        "Sqrt",
+	"math.tan":         "Tan",
+	"os.path.basename": "Base",
+	"os.path.dirname":  "Dir",
+	"os.path.join":     "Join",
+	"sys.exit":         "Exit",
+}
+
+// Module functions that are runtime helpers
+var moduleHelpers map[string]string = map[string]string{
+	"os.path.exists":    "pyExists",
+	"random.choice":     "pyChoice",
+	"random.randint":    "pyRandint",
+	"random.random":     "pyRandom",
+	"random.seed":       "pySeed",
+	"random.shuffle":    "pyShuffle",
+	"random.uniform":    "pyUniform",
+	"time.perf_counter": "pyTime",
+	"time.sleep":        "pySleep",
+	"time.time":         "pyTime",
+}
+
+// A call to a module function, as its Go equivalent or a runtime helper
+func (e *Emitter) emitModuleCall(ast Structure) (string, error) {
+	name := moduleName(ast.children[0], e.modules)
+	if isGoModule(name) {
+		return e.emitGoCall(ast, name)
+	}
+	fn := moduleFunctions[name]
+
+	args, err := e.moduleArguments(ast, fn)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case name == "math.floor" || name == "math.ceil":
+		e.require("math")
+		floor := "math.Floor(" + args[0] + ")"
+		if name == "math.ceil" {
+			floor = "math.Ceil(" + args[0] + ")"
+		}
+		if e.settings.bigInts {
+			e.helper("pyBigTrunc")
+			return "pyBigTrunc(" + floor + ")", nil
+		}
+		return "int(" + floor + ")", nil
+	case name == "math.isinf":
+		e.require("math")
+		return "math.IsInf(" + args[0] + ", 0)", nil
+	case name == "sys.exit" && len(args) == 0:
+		e.require("os")
+		return "os.Exit(0)", nil
+	case moduleHelpers[name] != "":
+		helper := moduleHelpers[name]
+		e.helper(helper)
+		if fn.varType == "int" {
+			return e.pythonInt(helper + "(" + strings.Join(args, ", ") + ")"), nil
+		}
+		return helper + "(" + strings.Join(args, ", ") + ")", nil
+	}
+
+	pkg := goImports[name[:strings.LastIndex(name, ".")]]
+	e.require(pkg)
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + goFunctions[name] + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// The arguments of a call to a module function, converted to the types of its
+// parameters
+func (e *Emitter) moduleArguments(ast Structure, fn Function) ([]string, error) {
+	args := []string{}
+	for i := 2; i+1 < len(ast.children); i += 2 {
+		temp, err := e.emit(ast.children[i])
+		if err != nil {
+			return nil, err
+		}
+		arg := Operand{strings.TrimSpace(temp), ast.children[i].varType, 3}
+
+		want := fn.params[len(fn.params)-1]
+		if len(args) < len(fn.params) {
+			want = fn.params[len(args)]
+		}
+		switch {
+		case want == "float64" && arg.varType == "float32":
+			arg.text = "float64(" + arg.text + ")"
+		case want == "float64":
+			arg = e.convert(arg, "float64")
+		case want == "int" && e.settings.bigInts && arg.varType == "int":
+			arg.text = "int(" + arg.text + ".Int64())"
+		case strings.HasPrefix(want, "dict"):
+			arg.text += ".m"
+		}
+		args = append(args, arg.text)
+	}
+	return args, nil
+}
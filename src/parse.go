@@ -1,17 +1,14 @@
 package main
 
-import (
-	"errors"
-	"log"
-)
+import "strings"
 
 type Parser struct {
-	curPos    int
-	curToken  Token
-	source    []Token
-	markers   []int
-	functions []Structure
-	funcLine  []string
+	curPos   int
+	curToken Token
+	source   []Token
+	markers  []int
+	funcLine []string
+	class    string // The class whose body is being parsed
 }
 
 func (p *Parser) setMarker() {
@@ -24,6 +21,11 @@ func (p *Parser) gotoMarker() {
 	p.markers = p.markers[:len(p.markers)-1]
 }
 
+// Forgets the last marker without going back to it
+func (p *Parser) dropMarker() {
+	p.markers = p.markers[:len(p.markers)-1]
+}
+
 func (p *Parser) nextToken() {
 	p.curPos++
 	if p.curPos >= len(p.source) {
@@ -35,7 +37,7 @@ func (p *Parser) nextToken() {
 
 func (p *Parser) rollBack() {
 	p.curPos--
-	if p.curPos < len(p.source) {
+	if p.curPos < 0 || p.curPos >= len(p.source) {
 		p.curToken = Token{} // Nil
 	} else {
 		p.curToken = p.source[p.curPos]
@@ -53,7 +55,7 @@ func (p *Parser) nextTokenNoNotes() []Structure {
 		} else if p.curToken.code == tokenCode["COMMENT_MULTI"] {
 			sc = structureCode["COMMENT_MULTI"]
 		}
-		sts = append(sts, Structure{sc, p.curToken.text, p.curToken.line, []Structure{}})
+		sts = append(sts, Structure{sc, p.curToken.text, p.curToken.line, []Structure{}, ""})
 		p.nextToken()
 	}
 	return sts
@@ -70,12 +72,24 @@ func (p *Parser) replaceIndents(input []Token) []Token {
 	indents := []int{0}
 	curIndex := 0
 
+	blank := []bool{true}
 	for i := 0; i < len(input); i++ {
 		if input[i].code == tokenCode["NEWLINE"] {
 			curIndex++
 			indents = append(indents, 0)
+			blank = append(blank, true)
 		} else if input[i].code == tokenCode["INDENT"] {
 			indents[curIndex]++
+		} else if input[i].code != tokenCode["COMMENT_ONE"] {
+			blank[curIndex] = false
+		}
+	}
+
+	// Blank lines don't end blocks, so they take the indent of the next line
+	// with code on it
+	for i := len(indents) - 2; i >= 0; i-- {
+		if blank[i] {
+			indents[i] = indents[i+1]
 		}
 	}
 
@@ -105,25 +119,36 @@ func (p *Parser) replaceIndents(input []Token) []Token {
 	return append(output, Token{tokenCode["NEWLINE"], "NEWLINE", len(indents) - 1})
 }
 
+// Imports have to come before the rest of the source, as they do in Go. The
+// GoType import only matters to Python, so it is dropped
 func (p *Parser) checkImport(program Structure) (Structure, error) {
-	if program.children[0].code != structureCode["ST_IMPORT"] {
-		return program, errors.New("(Parse [checkImport]) Source should start with \"from GoType import *\"")
-	}
-	if program.children[0].children[0].code != structureCode["K_FROM"] {
-		return program, errors.New("(Parse [checkImport]) Source should start with \"from GoType import *\"")
-	}
-	if program.children[0].children[1].text != "GoType" {
-		return program, errors.New("(Parse [checkImport]) Source should start with \"from GoType import *\"")
+	children := []Structure{}
+	started := false
+	for i := 0; i < len(program.children); i++ {
+		child := program.children[i]
+		switch child.code {
+		case structureCode["ST_IMPORT"]:
+			if started {
+				return program, createError([]string{"parse.go", "checkImport"}, "Imports must come before the rest of the source", child.line)
+			}
+			if child.children[0].code == structureCode["K_FROM"] && child.children[1].text == "GoType" {
+				continue
+			}
+		case structureCode["NEWLINE"], structureCode["COMMENT_ONE"], structureCode["COMMENT_MULTI"]:
+		default:
+			started = true
+		}
+		children = append(children, child)
 	}
-	program.children = program.children[1:]
+	program.children = children
 	return program, nil
 }
 
-func (p *Parser) parse(input []Token) Structure {
+func (p *Parser) parse(input []Token) (Structure, error) {
 	p.funcLine = []string{"parse.go", "parse"}
 
 	if len(input) == 0 {
-		log.Fatal(createError(p.funcLine, "Missing input", 0))
+		return Structure{}, createError(p.funcLine, "Missing input", 0)
 	}
 
 	p.source = input
@@ -132,15 +157,46 @@ func (p *Parser) parse(input []Token) Structure {
 
 	s, err := p.program()
 	if err != nil {
-		log.Fatal(err.Error())
+		return s, err
 	}
+	return p.checkImport(s)
+}
 
-	s, err = p.checkImport(s)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+// Takes the functions and classes of a program out of it, as Go only has them
+// at the top level, leaving a NEWLINE where each was. Functions defined in
+// another function stay, as they are closures, but classes can't. Definitions
+// inside another come before it
+func hoistDefinitions(s Structure) (Structure, []Structure) {
+	return hoist(s, false)
+}
 
-	return s
+func hoist(s Structure, inFunction bool) (Structure, []Structure) {
+	hoisted := []Structure{}
+	s.children = append([]Structure{}, s.children...)
+	for i := 0; i < len(s.children); i++ {
+		var inner []Structure
+		child := s.children[i]
+
+		// Methods stay in their class, but classes they define don't
+		if s.code == structureCode["ST_CLASS"] && child.code == structureCode["BLOCK"] {
+			child.children = append([]Structure{}, child.children...)
+			for j := 0; j < len(child.children); j++ {
+				child.children[j], inner = hoist(child.children[j], true)
+				hoisted = append(hoisted, inner...)
+			}
+			s.children[i] = child
+			continue
+		}
+
+		child, inner = hoist(child, inFunction || child.code == structureCode["ST_FUNCTION"])
+		hoisted = append(hoisted, inner...)
+		if child.code == structureCode["ST_CLASS"] || child.code == structureCode["ST_FUNCTION"] && !inFunction {
+			hoisted = append(hoisted, child)
+			child = createStructure("NEWLINE", "NEWLINE", child.line)
+		}
+		s.children[i] = child
+	}
+	return s, hoisted
 }
 
 func (p *Parser) program() (Structure, error) {
@@ -168,36 +224,50 @@ func (p *Parser) statement() (Structure, error) {
 	var s Structure
 
 	if p.curToken.code == tokenCode["K_IMPORT"] {
-		s = createStructure("ST_IMPORT", "", p.curToken.line)
+		s = createStructure("ST_IMPORT", "ST_IMPORT", p.curToken.line)
 		s.children = append(s.children, createStructure("K_IMPORT", p.curToken.text, p.curToken.line))
 		p.nextToken()
 
-		temp, err := p.checkToken("IDENTIFIER")
+		temp, err := p.dottedName()
 		if err != nil {
 			return s, err
 		}
 		s.children = append(s.children, temp)
+
+		if p.peek().code == tokenCode["K_AS"] {
+			p.nextToken()
+			s.children = append(s.children, createStructure("K_AS", p.curToken.text, p.curToken.line))
+			p.nextToken()
+			temp, err = p.checkToken("IDENTIFIER")
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+		}
 	} else if p.curToken.code == tokenCode["K_FROM"] {
 		s = createStructure("ST_IMPORT", "ST_IMPORT", p.curToken.line)
 		s.children = append(s.children, createStructure("K_FROM", p.curToken.text, p.curToken.line))
 		p.nextToken()
 
-		temps, err := p.checkTokenRange([]string{
-			"IDENTIFIER",
-			"K_IMPORT",
-		})
+		temp, err := p.dottedName()
 		if err != nil {
 			return s, err
 		}
-		s.children = append(s.children, temps...)
+		s.children = append(s.children, temp)
+		p.nextToken()
+		temp, err = p.checkToken("K_IMPORT")
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
 
-		temp, err := p.checkToken("UNDETERMINED")
+		temp, err = p.checkToken("MO_MUL")
 		if err != nil {
 			temp, err = p.checkToken("IDENTIFIER")
 			if err != nil {
-				return s, err
+				return s, createError(p.funcLine, "Expected ASTERISK or IDENTIFIER, got "+p.curToken.text, p.curToken.line)
 			}
-			s.children = append(s.children, temp)
 		} else {
 			if p.curToken.text != "*" {
 				return s, createError(p.funcLine, "Expected ASTERISK, got "+p.curToken.text, p.curToken.line)
@@ -205,19 +275,6 @@ func (p *Parser) statement() (Structure, error) {
 			temp.code = structureCode["ASTERISK"]
 		}
 		s.children = append(s.children, temp)
-
-		if p.curToken.code != tokenCode["UNDETERMINED"] {
-			if p.curToken.code != tokenCode["IDENTIFIER"] {
-				return s, createError(p.funcLine, "Expected ASTERISK, got "+p.curToken.text, p.curToken.line)
-			}
-			s.children = append(s.children, createStructure("IDENTIFIER", p.curToken.text, p.curToken.line))
-		} else {
-			if p.curToken.text == "*" {
-				s.children = append(s.children, createStructure("ASTERISK", p.curToken.text, p.curToken.line))
-			} else {
-				return s, createError(p.funcLine, "Expected ASTERISK, got"+p.curToken.text, p.curToken.line)
-			}
-		}
 	} else if p.curToken.code == tokenCode["COMMENT_ONE"] {
 		s = createStructure("COMMENT_ONE", p.curToken.text, p.curToken.line)
 	} else if p.curToken.code == tokenCode["COMMENT_MULTI"] {
@@ -229,59 +286,56 @@ func (p *Parser) statement() (Structure, error) {
 		s.children = append(s.children, createStructure("K_FOR", p.curToken.text, p.curToken.line))
 		p.nextToken()
 
-		temps, err := p.checkTokenRange([]string{
-			"IDENTIFIER",
-			"K_IN",
-			"IB_RANGE",
-			"L_PAREN",
-		})
-		if err != nil {
-			return s, err
-		}
-		s.children = append(s.children, temps...)
+		// The names the values are given, more than one for enumerate, zip
+		// and items
+		for {
+			temp, err := p.checkToken("IDENTIFIER")
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+			p.nextToken()
 
-		temp, err := p.checkTokenChoices([]string{
-			"L_INT",
-			"IDENTIFIER",
-		})
-		if err != nil {
-			return s, err
+			if p.curToken.code != tokenCode["SEP"] {
+				break
+			}
+			s.children = append(s.children, createStructure("SEP", p.curToken.text, p.curToken.line))
+			p.nextToken()
 		}
-		s.children = append(s.children, temp)
-		p.nextToken()
 
-		temp, err = p.checkToken("SEP")
+		temp, err := p.checkToken("K_IN")
 		if err != nil {
 			return s, err
 		}
 		s.children = append(s.children, temp)
 		p.nextToken()
 
-		temp, err = p.checkTokenChoices([]string{
-			"L_INT",
-			"IDENTIFIER",
-		})
+		temp, err = p.expression()
 		if err != nil {
 			return s, err
 		}
 		s.children = append(s.children, temp)
 		p.nextToken()
 
-		temps, err = p.checkTokenRange([]string{
-			"R_PAREN",
+		temps, err := p.checkTokenRange([]string{
 			"COLON",
 			"NEWLINE",
 		})
 		if err != nil {
 			return s, err
 		}
-		s.children = append(s.children, temps[:2]...)
+		s.children = append(s.children, temps[0])
 
 		temp, err = p.block()
 		if err != nil {
 			return s, err
 		}
 		s.children = append(s.children, temp)
+
+		s, err = p.loopElse(s)
+		if err != nil {
+			return s, err
+		}
 	} else if p.curToken.code == tokenCode["K_WHILE"] {
 		s = createStructure("ST_WHILE", "ST_WHILE", p.curToken.line)
 
@@ -309,8 +363,13 @@ func (p *Parser) statement() (Structure, error) {
 			return s, err
 		}
 		s.children = append(s.children, temp)
+
+		s, err = p.loopElse(s)
+		if err != nil {
+			return s, err
+		}
 	} else if p.curToken.code == tokenCode["K_IF"] {
-		s = createStructure("ST_IF_ELSE_BLOCK", "ST_IF_ELSE_BLOCK", p.curToken.line)
+		s = createStructure("IF_ELSE_BLOCK", "IF_ELSE_BLOCK", p.curToken.line)
 
 		temp, err := p.s_if()
 		if err != nil {
@@ -318,87 +377,162 @@ func (p *Parser) statement() (Structure, error) {
 		}
 		s.children = append(s.children, temp)
 
-		p.setMarker()
-		p.nextTokenNoNotes()
+		for {
+			p.setMarker()
+			p.nextTokenNoNotes()
 
-		extra_found := false
-		for p.curToken.code == tokenCode["K_ELIF"] {
-			extra_found = true
-			temp, err = p.s_elif()
-			if err != nil {
-				return s, err
+			if p.curToken.code == tokenCode["K_ELIF"] {
+				p.dropMarker()
+				temp, err = p.s_elif()
+				if err != nil {
+					return s, err
+				}
+				s.children = append(s.children, temp)
+				continue
 			}
-			s.children = append(s.children, temp)
-			p.nextTokenNoNotes()
-		}
 
-		if p.curToken.code == tokenCode["K_ELSE"] {
-			extra_found = true
-			temp, err = p.s_else()
-			if err != nil {
-				return s, err
+			if p.curToken.code == tokenCode["K_ELSE"] {
+				p.dropMarker()
+				temp, err = p.s_else()
+				if err != nil {
+					return s, err
+				}
+				s.children = append(s.children, temp)
+				break
 			}
-			s.children = append(s.children, temp)
-		}
-		if !extra_found {
+
 			p.gotoMarker()
+			break
 		}
-	} else if p.curToken.code == tokenCode["IB_PRINT"] {
-		s = createStructure("ST_CALL", "ST_CALL", p.curToken.line)
-		s.children = append(s.children, createStructure("IB_PRINT", p.curToken.text, p.curToken.line))
+	} else if p.curToken.code == tokenCode["K_TRY"] {
+		s = createStructure("ST_TRY", "ST_TRY", p.curToken.line)
+		s.children = append(s.children, createStructure("K_TRY", p.curToken.text, p.curToken.line))
 		p.nextToken()
 
-		temp, err := p.checkToken("L_PAREN")
+		temps, err := p.checkTokenRange([]string{
+			"COLON",
+			"NEWLINE",
+		})
 		if err != nil {
 			return s, err
 		}
-		s.children = append(s.children, temp)
-		p.nextToken()
+		s.children = append(s.children, temps[0])
 
-		temp, err = p.checkTokenChoices([]string{
-			"L_BOOL",
-			"L_INT",
-			"L_STRING",
-			"IDENTIFIER",
-		})
+		temp, err := p.block()
 		if err != nil {
 			return s, err
 		}
 		s.children = append(s.children, temp)
-		p.nextToken()
 
-		temp, err = p.checkToken("R_PAREN")
+		for {
+			p.setMarker()
+			p.nextTokenNoNotes()
+			if p.curToken.code != tokenCode["K_EXCEPT"] {
+				p.gotoMarker()
+				break
+			}
+			p.dropMarker()
+
+			temp, err = p.s_except()
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+		}
+
+		if len(s.children) == 3 {
+			return s, createError(p.funcLine, "Expected K_EXCEPT after try", s.line)
+		}
+	} else if p.curToken.code == tokenCode["K_RAISE"] {
+		s = createStructure("ST_RAISE", "ST_RAISE", p.curToken.line)
+		s.children = append(s.children, createStructure("K_RAISE", p.curToken.text, p.curToken.line))
+
+		if p.peek().code != tokenCode["NEWLINE"] && p.peek().code != tokenCode["ANTI_COLON"] {
+			p.nextToken()
+
+			temp, err := p.expression()
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+		}
+	} else if p.curToken.code == tokenCode["IB_PRINT"] {
+		temp, err := p.call()
 		if err != nil {
 			return s, err
 		}
-		s.children = append(s.children, temp)
+		s = temp
 	} else if p.curToken.code == tokenCode["IDENTIFIER"] {
 		if p.peek().code == tokenCode["COLON"] {
 			s = createStructure("ST_DECLARATION", "ST_DECLARATION", p.curToken.line)
 			s.children = append(s.children, createStructure("IDENTIFIER", p.curToken.text, p.curToken.line))
 			p.nextToken()
 
-			temps, err := p.checkTokenRange([]string{
-				"COLON",
-				"IDENTIFIER",
-				"ASSIGN",
-			})
+			temp, err := p.checkToken("COLON")
 			if err != nil {
 				return s, err
 			}
-			s.children = append(s.children, temps...)
+			s.children = append(s.children, temp)
+			p.nextToken()
 
-			temp, err := p.expression()
+			temp, err = p.annotation()
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+			p.nextToken()
+
+			temp, err = p.checkToken("ASSIGN")
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+			p.nextToken()
+
+			temp, err = p.expression()
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+		} else if p.peek().code == tokenCode["SEP"] {
+			s = createStructure("ST_UNPACK", "ST_UNPACK", p.curToken.line)
+
+			for {
+				temp, err := p.checkToken("IDENTIFIER")
+				if err != nil {
+					return s, err
+				}
+				s.children = append(s.children, temp)
+				p.nextToken()
+
+				if p.curToken.code != tokenCode["SEP"] {
+					break
+				}
+				s.children = append(s.children, createStructure("SEP", p.curToken.text, p.curToken.line))
+				p.nextToken()
+			}
+
+			temp, err := p.checkToken("ASSIGN")
 			if err != nil {
 				return s, err
 			}
 			s.children = append(s.children, temp)
-		} else if p.peek().code == tokenCode["ASSIGN"] {
+			p.nextToken()
+
+			temps, err := p.expressionList()
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temps...)
+		} else if p.peek().code == tokenCode["ASSIGN"] || p.peek().code == tokenCode["AUG_ASSIGN"] {
 			s = createStructure("ST_MANIPULATION", "ST_MANIPULATION", p.curToken.line)
 			s.children = append(s.children, createStructure("IDENTIFIER", p.curToken.text, p.curToken.line))
 			p.nextToken()
 
-			temp, err := p.checkToken("ASSIGN")
+			temp, err := p.checkTokenChoices([]string{
+				"ASSIGN",
+				"AUG_ASSIGN",
+			})
 			if err != nil {
 				return s, err
 			}
@@ -410,6 +544,38 @@ func (p *Parser) statement() (Structure, error) {
 				return s, err
 			}
 			s.children = append(s.children, temp)
+		} else if p.peek().code == tokenCode["ACCESSOR"] {
+			p.setMarker()
+			target, err := p.attribute()
+			if err != nil {
+				p.dropMarker()
+				return s, err
+			}
+			p.nextToken()
+
+			if p.curToken.code == tokenCode["ASSIGN"] || p.curToken.code == tokenCode["AUG_ASSIGN"] {
+				p.dropMarker()
+				s = createStructure("ST_MANIPULATION", "ST_MANIPULATION", target.line)
+				operator, _ := p.checkTokenChoices([]string{
+					"ASSIGN",
+					"AUG_ASSIGN",
+				})
+				s.children = append(s.children, target, operator)
+				p.nextToken()
+
+				temp, err := p.expression()
+				if err != nil {
+					return s, err
+				}
+				s.children = append(s.children, temp)
+			} else {
+				p.gotoMarker()
+				temp, err := p.call()
+				if err != nil {
+					return s, err
+				}
+				s = temp
+			}
 		} else if p.peek().code == tokenCode["L_PAREN"] {
 			temp, err := p.call()
 			if err != nil {
@@ -438,16 +604,31 @@ func (p *Parser) statement() (Structure, error) {
 
 		p.nextToken()
 
-		for p.curToken.code == tokenCode["IDENTIFIER"] {
-			temps, err := p.checkTokenRange([]string{
-				"IDENTIFIER",
-				"COLON",
-				"IDENTIFIER",
-			})
+		// The self of a method isn't annotated, it's always the class
+		class := p.class
+		if class != "" && p.curToken.code == tokenCode["IDENTIFIER"] && p.curToken.text == "self" && p.peek().code != tokenCode["COLON"] {
+			temp = createStructure("PARAMETER", "PARAMETER", p.curToken.line)
+			temp.children = append(temp.children,
+				createStructure("IDENTIFIER", p.curToken.text, p.curToken.line),
+				createStructure("COLON", ":", p.curToken.line),
+				createStructure("IDENTIFIER", class, p.curToken.line),
+			)
+			s.children = append(s.children, temp)
+			p.nextToken()
+
+			if p.curToken.code == tokenCode["SEP"] {
+				s.children = append(s.children, createStructure("SEP", p.curToken.text, p.curToken.line))
+				p.nextToken()
+			}
+		}
+
+		for p.curToken.code == tokenCode["IDENTIFIER"] || p.curToken.code == tokenCode["MO_MUL"] {
+			temp, err = p.parameter()
 			if err != nil {
 				return s, err
 			}
-			s.children = append(s.children, temps...)
+			s.children = append(s.children, temp)
+			p.nextToken()
 
 			temp, err = p.checkToken("SEP")
 			if err != nil {
@@ -467,12 +648,13 @@ func (p *Parser) statement() (Structure, error) {
 		s.children = append(s.children, temps...)
 		p.nextToken()
 
-		temp, err = p.checkTokenChoices([]string{
-			"IDENTIFIER",
-			"L_NULL",
-		})
-		if err != nil {
-			return s, err
+		if p.curToken.code == tokenCode["L_NULL"] {
+			temp = createStructure("L_NULL", p.curToken.text, p.curToken.line)
+		} else {
+			temp, err = p.annotation()
+			if err != nil {
+				return s, err
+			}
 		}
 		s.children = append(s.children, temp)
 		p.nextToken()
@@ -486,60 +668,162 @@ func (p *Parser) statement() (Structure, error) {
 		}
 		s.children = append(s.children, temps[0])
 
+		p.class = ""
 		temp, err = p.block()
+		p.class = class
 		if err != nil {
 			return s, err
 		}
 		s.children = append(s.children, temp)
 
-		p.functions = append(p.functions, s)
-
 		p.funcLine = p.funcLine[:len(p.funcLine)-1]
-		return createStructure("NEWLINE", "NEWLINE", p.curToken.line), nil
-	} else if p.curToken.code == tokenCode["K_RETURN"] {
-		s = createStructure("ST_RETURN", "ST_RETURN", p.curToken.line)
-		s.children = append(s.children, createStructure("K_RETURN", p.curToken.text, p.curToken.line))
+		return s, nil
+	} else if p.curToken.code == tokenCode["K_CLASS"] {
+		s = createStructure("ST_CLASS", "ST_CLASS", p.curToken.line)
+		s.children = append(s.children, createStructure("K_CLASS", p.curToken.text, p.curToken.line))
 		p.nextToken()
 
-		temp, err := p.checkTokenChoices([]string{
+		temps, err := p.checkTokenRange([]string{
 			"IDENTIFIER",
-			"L_BOOL",
-			"L_INT",
-			"L_STRING",
+			"COLON",
+			"NEWLINE",
 		})
 		if err != nil {
 			return s, err
 		}
-		s.children = append(s.children, temp)
-	}
-
-	if len(s.children) == 0 {
-		s = createStructure("ILLEGAL", "ILLEGAL + "+p.curToken.text, p.curToken.line)
-	}
-
-	p.funcLine = p.funcLine[:len(p.funcLine)-1]
-	return s, nil
-}
-
-func (p *Parser) block() (Structure, error) {
-	p.funcLine = append(p.funcLine, "block")
-	block := createStructure("BLOCK", "BLOCK", p.curToken.line)
+		s.children = append(s.children, temps[:2]...)
 
-	for p.curPos < len(p.source) {
-		statement, err := p.statement()
+		outer := p.class
+		p.class = temps[0].text
+		temp, err := p.block()
+		p.class = outer
 		if err != nil {
-			return block, err
+			return s, err
 		}
-		block.children = append(block.children, statement)
+		s.children = append(s.children, temp)
 
-		//p.nextToken()
+		p.funcLine = p.funcLine[:len(p.funcLine)-1]
+		return s, nil
+	} else if p.curToken.code == tokenCode["K_WITH"] {
+		s = createStructure("ST_WITH", "ST_WITH", p.curToken.line)
+		s.children = append(s.children, createStructure("K_WITH", p.curToken.text, p.curToken.line))
+		p.nextToken()
 
-		if p.peek().code == tokenCode["ANTI_COLON"] {
+		for {
+			item := createStructure("WITH_ITEM", "WITH_ITEM", p.curToken.line)
+			temp, err := p.expression()
+			if err != nil {
+				return s, err
+			}
+			item.children = append(item.children, temp)
 			p.nextToken()
-			break
-		}
 
-		block.children = append(block.children, p.nextTokenNoNotes()...)
+			if p.curToken.code == tokenCode["K_AS"] {
+				temps, err := p.checkTokenRange([]string{
+					"K_AS",
+					"IDENTIFIER",
+				})
+				if err != nil {
+					return s, err
+				}
+				item.children = append(item.children, temps...)
+			}
+			s.children = append(s.children, item)
+
+			if p.curToken.code != tokenCode["SEP"] {
+				break
+			}
+			s.children = append(s.children, createStructure("SEP", p.curToken.text, p.curToken.line))
+			p.nextToken()
+		}
+
+		temps, err := p.checkTokenRange([]string{
+			"COLON",
+			"NEWLINE",
+		})
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temps[0])
+
+		temp, err := p.block()
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+	} else if p.curToken.code == tokenCode["K_BREAK"] {
+		s = createStructure("ST_BREAK", "ST_BREAK", p.curToken.line)
+		s.children = append(s.children, createStructure("K_BREAK", p.curToken.text, p.curToken.line))
+	} else if p.curToken.code == tokenCode["K_CONTINUE"] {
+		s = createStructure("ST_CONTINUE", "ST_CONTINUE", p.curToken.line)
+		s.children = append(s.children, createStructure("K_CONTINUE", p.curToken.text, p.curToken.line))
+	} else if p.curToken.code == tokenCode["K_PASS"] {
+		s = createStructure("ST_PASS", "ST_PASS", p.curToken.line)
+		s.children = append(s.children, createStructure("K_PASS", p.curToken.text, p.curToken.line))
+	} else if p.curToken.code == tokenCode["K_NONLOCAL"] || p.curToken.code == tokenCode["K_GLOBAL"] {
+		// The names a function assigns to outside itself
+		kind := "NONLOCAL"
+		if p.curToken.code == tokenCode["K_GLOBAL"] {
+			kind = "GLOBAL"
+		}
+		s = createStructure("ST_"+kind, "ST_"+kind, p.curToken.line)
+		s.children = append(s.children, createStructure("K_"+kind, p.curToken.text, p.curToken.line))
+		for {
+			p.nextToken()
+			temp, err := p.checkToken("IDENTIFIER")
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temp)
+
+			if p.peek().code != tokenCode["SEP"] {
+				break
+			}
+			p.nextToken()
+			s.children = append(s.children, createStructure("SEP", p.curToken.text, p.curToken.line))
+		}
+	} else if p.curToken.code == tokenCode["K_RETURN"] {
+		s = createStructure("ST_RETURN", "ST_RETURN", p.curToken.line)
+		s.children = append(s.children, createStructure("K_RETURN", p.curToken.text, p.curToken.line))
+
+		if p.peek().code != tokenCode["NEWLINE"] && p.peek().code != tokenCode["ANTI_COLON"] {
+			p.nextToken()
+
+			temps, err := p.expressionList()
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temps...)
+		}
+	}
+
+	if len(s.children) == 0 {
+		s = createStructure("ILLEGAL", "ILLEGAL + "+p.curToken.text, p.curToken.line)
+	}
+
+	p.funcLine = p.funcLine[:len(p.funcLine)-1]
+	return s, nil
+}
+
+func (p *Parser) block() (Structure, error) {
+	p.funcLine = append(p.funcLine, "block")
+	block := createStructure("BLOCK", "BLOCK", p.curToken.line)
+
+	for p.curPos < len(p.source) {
+		statement, err := p.statement()
+		if err != nil {
+			return block, err
+		}
+		block.children = append(block.children, statement)
+
+		//p.nextToken()
+
+		if p.peek().code == tokenCode["ANTI_COLON"] {
+			p.nextToken()
+			break
+		}
+
+		block.children = append(block.children, p.nextTokenNoNotes()...)
 
 	}
 
@@ -550,40 +834,351 @@ func (p *Parser) block() (Structure, error) {
 }
 
 func (p *Parser) call() (Structure, error) {
+	s := createStructure("ST_CALL", "ST_CALL", p.curToken.line)
+
+	var temp Structure
 	var err error
+	if (p.curToken.code == tokenCode["IDENTIFIER"] || p.curToken.code == tokenCode["L_STRING"]) && p.peek().code == tokenCode["ACCESSOR"] {
+		// Methods, called on an ATTRIBUTE
+		temp, err = p.attribute()
+	} else {
+		temp, err = p.checkTokenChoices([]string{
+			"IDENTIFIER",
+			"IB_PRINT",
+			"IB_RANGE",
+		})
+	}
+	if err != nil {
+		return s, err
+	}
+	if temp.code == structureCode["IDENTIFIER"] || temp.code == structureCode["IB_RANGE"] {
+		temp.code = structureCode["FUNC_NAME"]
+	}
+	s.children = append(s.children, temp)
+	p.nextToken()
 
-	s := createStructure("ST_CALL", "ST_CALL", p.curToken.line)
+	s, err = p.arguments(s)
+	if err != nil {
+		return s, err
+	}
+
+	// Methods called on the result, such as s.strip().upper()
+	for p.peek().code == tokenCode["ACCESSOR"] {
+		p.nextToken()
+		callee := createStructure("ATTRIBUTE", "ATTRIBUTE", p.curToken.line)
+		callee.children = append(callee.children, s)
+		temps, err := p.checkTokenRange([]string{
+			"ACCESSOR",
+			"IDENTIFIER",
+		})
+		if err != nil {
+			return s, err
+		}
+		callee.children = append(callee.children, temps...)
+
+		s = createStructure("ST_CALL", "ST_CALL", callee.line)
+		s.children = append(s.children, callee)
+		s, err = p.arguments(s)
+		if err != nil {
+			return s, err
+		}
+	}
 
-	temp, err := p.checkToken("IDENTIFIER")
+	return s, nil
+}
+
+// The arguments of a call, from its opening bracket to its closing one
+func (p *Parser) arguments(s Structure) (Structure, error) {
+	temp, err := p.checkToken("L_PAREN")
 	if err != nil {
 		return s, err
 	}
-	temp.code = structureCode["FUNC_NAME"]
 	s.children = append(s.children, temp)
 	p.nextToken()
 
-	temp, err = p.checkToken("L_PAREN")
+	for p.curToken.code != tokenCode["R_PAREN"] {
+		temp, err = p.argument()
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+
+		temp, err = p.checkToken("SEP")
+		if err != nil {
+			break
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+	}
+
+	temp, err = p.checkToken("R_PAREN")
 	if err != nil {
 		return s, err
 	}
 	s.children = append(s.children, temp)
+
+	return s, nil
+}
+
+// A parameter of a function, either name: type, name: type = default, or
+// *name: type
+func (p *Parser) parameter() (Structure, error) {
+	s := createStructure("PARAMETER", "PARAMETER", p.curToken.line)
+
+	variadic := p.curToken.code == tokenCode["MO_MUL"]
+	if variadic {
+		s.children = append(s.children, createStructure("MO_MUL", p.curToken.text, p.curToken.line))
+		p.nextToken()
+	}
+
+	temps, err := p.checkTokenRange([]string{
+		"IDENTIFIER",
+		"COLON",
+	})
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temps...)
+
+	temp, err := p.annotation()
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+
+	if variadic || p.peek().code != tokenCode["ASSIGN"] {
+		return s, nil
+	}
 	p.nextToken()
 
-	temp, err = p.checkTokenChoices([]string{
+	s.children = append(s.children, createStructure("ASSIGN", p.curToken.text, p.curToken.line))
+	p.nextToken()
+
+	temp, err = p.expression()
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+
+	return s, nil
+}
+
+// A type annotation, such as int or tuple[int, int], read as one IDENTIFIER
+// holding the whole type
+func (p *Parser) annotation() (Structure, error) {
+	s, err := p.checkToken("IDENTIFIER")
+	if err != nil {
+		return s, err
+	}
+	if p.peek().code != tokenCode["L_BLOCK"] {
+		return s, nil
+	}
+	p.nextToken()
+
+	args, err := p.annotationList()
+	if err != nil {
+		return s, err
+	}
+	if args == "[]" {
+		return s, createError(p.funcLine, "Expected a type in the brackets after "+s.text, s.line)
+	}
+	s.text += args
+
+	return s, nil
+}
+
+// The types in brackets in an annotation, from the opening bracket to the
+// closing one. They can be lists themselves, for the parameters of a
+// Callable, which can be empty
+func (p *Parser) annotationList() (string, error) {
+	p.nextToken()
+	args := []string{}
+	for {
+		choices := []string{"IDENTIFIER", "L_NULL", "L_BLOCK"}
+		if len(args) == 0 {
+			choices = append(choices, "R_BLOCK")
+		}
+		temp, err := p.checkTokenChoices(choices)
+		if err != nil {
+			return "", err
+		}
+		if temp.code == structureCode["R_BLOCK"] {
+			break // An empty list
+		}
+		if temp.code == structureCode["L_BLOCK"] {
+			temp.text, err = p.annotationList()
+		} else if temp.code == structureCode["IDENTIFIER"] {
+			temp, err = p.annotation()
+		}
+		if err != nil {
+			return "", err
+		}
+		args = append(args, temp.text)
+		p.nextToken()
+
+		if p.curToken.code != tokenCode["SEP"] {
+			break
+		}
+		p.nextToken()
+	}
+
+	_, err := p.checkToken("R_BLOCK")
+	if err != nil {
+		return "", err
+	}
+	return "[" + strings.Join(args, ", ") + "]", nil
+}
+
+// Expressions separated by commas, such as in a return
+func (p *Parser) expressionList() ([]Structure, error) {
+	sts := []Structure{}
+	for {
+		temp, err := p.expression()
+		if err != nil {
+			return sts, err
+		}
+		sts = append(sts, temp)
+
+		if p.peek().code != tokenCode["SEP"] {
+			return sts, nil
+		}
+		p.nextToken()
+		sts = append(sts, createStructure("SEP", p.curToken.text, p.curToken.line))
+		p.nextToken()
+	}
+}
+
+// An argument in a call, either an expression, or name=expression
+func (p *Parser) argument() (Structure, error) {
+	if p.curToken.code != tokenCode["IDENTIFIER"] || p.peek().code != tokenCode["ASSIGN"] {
+		return p.expression()
+	}
+
+	s := createStructure("KEYWORD_ARG", "KEYWORD_ARG", p.curToken.line)
+
+	temps, err := p.checkTokenRange([]string{
 		"IDENTIFIER",
+		"ASSIGN",
+	})
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temps...)
+
+	temp, err := p.expression()
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+
+	return s, nil
+}
+
+// A single value in an expression
+func (p *Parser) operand() (Structure, error) {
+	if p.curToken.code == tokenCode["IB_PRINT"] || p.curToken.code == tokenCode["IB_RANGE"] || (p.curToken.code == tokenCode["IDENTIFIER"] && p.peek().code == tokenCode["L_PAREN"]) {
+		return p.call()
+	}
+
+	if p.curToken.code == tokenCode["L_BLOCK"] {
+		return p.list()
+	}
+
+	if p.curToken.code == tokenCode["L_SQUIRLY"] {
+		return p.dict()
+	}
+
+	if p.curToken.code == tokenCode["K_LAMBDA"] {
+		return p.lambda()
+	}
+
+	// Strings only have methods, such as ", ".join(names)
+	if p.curToken.code == tokenCode["L_STRING"] && p.peek().code == tokenCode["ACCESSOR"] {
+		return p.call()
+	}
+
+	if p.curToken.code == tokenCode["IDENTIFIER"] && p.peek().code == tokenCode["ACCESSOR"] {
+		p.setMarker()
+		s, err := p.attribute()
+		if err != nil {
+			p.dropMarker()
+			return s, err
+		}
+		if p.peek().code != tokenCode["L_PAREN"] {
+			p.dropMarker()
+			return s, nil
+		}
+		p.gotoMarker()
+		return p.call()
+	}
+
+	// Negative numbers
+	if p.curToken.code == tokenCode["MO_SUB"] && p.peek().code == tokenCode["L_INT"] {
+		p.nextToken()
+		return createStructure("L_INT", "-"+p.curToken.text, p.curToken.line), nil
+	}
+
+	return p.checkTokenChoices([]string{
 		"L_BOOL",
 		"L_INT",
 		"L_STRING",
+		"L_NULL",
+		"IDENTIFIER",
 	})
+}
 
-	for err == nil {
-		if p.peek().code == tokenCode["L_PAREN"] { // We're dealing with a call
-			temp, err = p.call()
-			if err != nil {
-				return s, err
-			}
+// A lambda, such as lambda a, b: a + b. Its parameters have no types, as
+// they come from the Callable it is used as
+func (p *Parser) lambda() (Structure, error) {
+	p.funcLine = append(p.funcLine, "lambda")
+	s := createStructure("LAMBDA", "LAMBDA", p.curToken.line)
+	s.children = append(s.children, createStructure("K_LAMBDA", p.curToken.text, p.curToken.line))
+	p.nextToken()
+
+	for p.curToken.code != tokenCode["COLON"] {
+		temp, err := p.checkToken("IDENTIFIER")
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+
+		if p.curToken.code != tokenCode["SEP"] {
+			break
 		}
+		s.children = append(s.children, createStructure("SEP", p.curToken.text, p.curToken.line))
+		p.nextToken()
+	}
+
+	temp, err := p.checkToken("COLON")
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+	p.nextToken()
+
+	temp, err = p.expression()
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+
+	p.funcLine = p.funcLine[:len(p.funcLine)-1]
+	return s, nil
+}
+
+// A list literal, such as [1, 2, 3]
+func (p *Parser) list() (Structure, error) {
+	s := createStructure("LIST", "LIST", p.curToken.line)
+	s.children = append(s.children, createStructure("L_BLOCK", p.curToken.text, p.curToken.line))
+	p.nextToken()
 
+	for p.curToken.code != tokenCode["R_BLOCK"] {
+		temp, err := p.expression()
+		if err != nil {
+			return s, err
+		}
 		s.children = append(s.children, temp)
 		p.nextToken()
 
@@ -593,16 +1188,54 @@ func (p *Parser) call() (Structure, error) {
 		}
 		s.children = append(s.children, temp)
 		p.nextToken()
+	}
 
-		temp, err = p.checkTokenChoices([]string{
-			"IDENTIFIER",
-			"L_BOOL",
-			"L_INT",
-			"L_STRING",
-		})
+	temp, err := p.checkToken("R_BLOCK")
+	if err != nil {
+		return s, err
 	}
+	s.children = append(s.children, temp)
 
-	temp, err = p.checkToken("R_PAREN")
+	return s, nil
+}
+
+// A dict literal, such as {"a": 1, "b": 2}
+func (p *Parser) dict() (Structure, error) {
+	s := createStructure("DICT", "DICT", p.curToken.line)
+	s.children = append(s.children, createStructure("L_SQUIRLY", p.curToken.text, p.curToken.line))
+	p.nextToken()
+
+	for p.curToken.code != tokenCode["R_SQUIRLY"] {
+		temp, err := p.expression()
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+
+		temp, err = p.checkToken("COLON")
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+
+		temp, err = p.expression()
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+
+		temp, err = p.checkToken("SEP")
+		if err != nil {
+			break
+		}
+		s.children = append(s.children, temp)
+		p.nextToken()
+	}
+
+	temp, err := p.checkToken("R_SQUIRLY")
 	if err != nil {
 		return s, err
 	}
@@ -611,6 +1244,31 @@ func (p *Parser) call() (Structure, error) {
 	return s, nil
 }
 
+// Names joined with dots, such as sys.stderr, ending on the last name. The
+// first can be a string, for its methods
+func (p *Parser) attribute() (Structure, error) {
+	s := createStructure("ATTRIBUTE", "ATTRIBUTE", p.curToken.line)
+	first := "IDENTIFIER"
+	if p.curToken.code == tokenCode["L_STRING"] {
+		first = "L_STRING"
+	}
+	s.children = append(s.children, createStructure(first, p.curToken.text, p.curToken.line))
+
+	for p.peek().code == tokenCode["ACCESSOR"] {
+		p.nextToken()
+		temps, err := p.checkTokenRange([]string{
+			"ACCESSOR",
+			"IDENTIFIER",
+		})
+		if err != nil {
+			return s, err
+		}
+		s.children = append(s.children, temps...)
+		p.rollBack()
+	}
+	return s, nil
+}
+
 func (p *Parser) s_if() (Structure, error) {
 	p.funcLine = append(p.funcLine, "s_if")
 	s := createStructure("ST_IF", "ST_IF", p.curToken.line)
@@ -685,6 +1343,53 @@ func (p *Parser) s_elif() (Structure, error) {
 	return s, nil
 }
 
+// An except clause, except, except Type, or except Type as name
+func (p *Parser) s_except() (Structure, error) {
+	p.funcLine = append(p.funcLine, "s_except")
+	s := createStructure("ST_EXCEPT", "ST_EXCEPT", p.curToken.line)
+
+	temp, err := p.checkToken("K_EXCEPT")
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+	p.nextToken()
+
+	if p.curToken.code == tokenCode["IDENTIFIER"] {
+		s.children = append(s.children, createStructure("IDENTIFIER", p.curToken.text, p.curToken.line))
+		p.nextToken()
+
+		if p.curToken.code == tokenCode["K_AS"] {
+			temps, err := p.checkTokenRange([]string{
+				"K_AS",
+				"IDENTIFIER",
+			})
+			if err != nil {
+				return s, err
+			}
+			s.children = append(s.children, temps...)
+		}
+	}
+
+	temps, err := p.checkTokenRange([]string{
+		"COLON",
+		"NEWLINE",
+	})
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temps[0])
+
+	temp, err = p.block()
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+
+	p.funcLine = p.funcLine[:len(p.funcLine)-1]
+	return s, nil
+}
+
 func (p *Parser) s_else() (Structure, error) {
 	p.funcLine = append(p.funcLine, "s_else")
 	s := createStructure("ST_ELSE", "ST_ELSE", p.curToken.line)
@@ -715,23 +1420,32 @@ func (p *Parser) s_else() (Structure, error) {
 	return s, nil
 }
 
+// An else clause after a for or while loop, which runs if the loop ends
+// without a break
+func (p *Parser) loopElse(s Structure) (Structure, error) {
+	p.setMarker()
+	p.nextTokenNoNotes()
+	if p.curToken.code != tokenCode["K_ELSE"] {
+		p.gotoMarker()
+		return s, nil
+	}
+	p.dropMarker()
+
+	temp, err := p.s_else()
+	if err != nil {
+		return s, err
+	}
+	s.children = append(s.children, temp)
+	return s, nil
+}
+
 func (p *Parser) expression() (Structure, error) {
 	p.funcLine = append(p.funcLine, "expression")
 	s := createStructure("EXPRESSION", "EXPRESSION", p.curToken.line)
 
-	p.setMarker()
-	temp, err := p.call()
+	temp, err := p.operand()
 	if err != nil {
-		p.gotoMarker()
-		temp, err = p.checkTokenChoices([]string{
-			"L_BOOL",
-			"L_INT",
-			"L_STRING",
-			"IDENTIFIER",
-		})
-		if err != nil {
-			return s, err
-		}
+		return s, err
 	}
 	s.children = append(s.children, temp)
 	p.nextToken()
@@ -742,25 +1456,17 @@ func (p *Parser) expression() (Structure, error) {
 		"MO_MUL",
 		"MO_DIV",
 		"MO_MODULO",
+		"MO_FLOOR_DIV",
+		"MO_POW",
 	})
 
 	for err == nil {
 		s.children = append(s.children, temp)
 		p.nextToken()
 
-		p.setMarker()
-		temp, err = p.call()
+		temp, err = p.operand()
 		if err != nil {
-			p.gotoMarker()
-			temp, err = p.checkTokenChoices([]string{
-				"L_BOOL",
-				"L_INT",
-				"L_STRING",
-				"IDENTIFIER",
-			})
-			if err != nil {
-				return s, err
-			}
+			return s, err
 		}
 		s.children = append(s.children, temp)
 		p.nextToken()
@@ -771,6 +1477,8 @@ func (p *Parser) expression() (Structure, error) {
 			"MO_MUL",
 			"MO_DIV",
 			"MO_MODULO",
+			"MO_FLOOR_DIV",
+			"MO_POW",
 		})
 	}
 	p.rollBack()
@@ -814,6 +1522,26 @@ func (p *Parser) comparison() (Structure, error) {
 	return s, nil
 }
 
+// A module name such as go.net.url, kept as one IDENTIFIER
+func (p *Parser) dottedName() (Structure, error) {
+	p.funcLine = append(p.funcLine, "dottedName")
+	name, err := p.checkToken("IDENTIFIER")
+	if err != nil {
+		return name, err
+	}
+	for p.peek().code == tokenCode["ACCESSOR"] {
+		p.nextToken()
+		p.nextToken()
+		part, err := p.checkToken("IDENTIFIER")
+		if err != nil {
+			return name, err
+		}
+		name.text += "." + part.text
+	}
+	p.funcLine = p.funcLine[:len(p.funcLine)-1]
+	return name, nil
+}
+
 func (p *Parser) checkTokenRange(tokenKeys []string) ([]Structure, error) {
 	p.funcLine = append(p.funcLine, "checkTokenRange")
 	structures := []Structure{}
@@ -843,7 +1571,9 @@ func (p *Parser) checkTokenChoices(tokenKeys []string) (Structure, error) {
 		errText += " or "
 	}
 	errText = errText[:len(errText)-4]
-	return Structure{}, createError(p.funcLine, "Expected "+errText+", got "+p.curToken.text, p.curToken.line)
+	err := createError(p.funcLine, "Expected "+errText+", got "+p.curToken.text, p.curToken.line)
+	p.funcLine = p.funcLine[:len(p.funcLine)-1]
+	return Structure{}, err
 }
 
 func (p *Parser) checkToken(tokenKey string) (Structure, error) {
@@ -852,5 +1582,7 @@ func (p *Parser) checkToken(tokenKey string) (Structure, error) {
 		p.funcLine = p.funcLine[:len(p.funcLine)-1]
 		return createStructure(tokenKey, p.curToken.text, p.curToken.line), nil
 	}
-	return Structure{}, createError(p.funcLine, "Expected "+tokenKey+", got "+p.curToken.text, p.curToken.line)
+	err := createError(p.funcLine, "Expected "+tokenKey+", got "+p.curToken.text, p.curToken.line)
+	p.funcLine = p.funcLine[:len(p.funcLine)-1]
+	return Structure{}, err
 }
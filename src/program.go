@@ -0,0 +1,828 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Python file of the program. Local modules are compiled into the same Go
+// package as the entry file. The functions, classes and module level variables
+// of the others are given their module's name in Go, such as utils_helper, so
+// two modules can define the same name
+type Module struct {
+	name       string
+	input      []byte
+	imports    []Structure       // Imports of Python modules and Go packages
+	functions  []Structure       // Functions and classes, hoisted by the parser
+	body       []Structure       // The rest of the top level code
+	defines    map[string]bool   // Names of its functions and classes
+	variables  map[string]string // Its module level variables, and their types in Go
+	visible    map[string]string // Names from local modules it has imported, and the module each is from
+	prefixes   map[string]string // Local modules imported whole, by the name they are used with
+	locals     []string          // The local modules it imports
+	entry      bool              // Whether it is the entry file, whose names are kept in Go
+	key        string            // Identifies the source and how it is compiled, for the build cache
+	cached     *CacheEntry       // What the last build made of it
+	clean      bool              // Whether the cached Go can be used, so it isn't parsed
+	signatures []CachedStructure // Its functions and classes before they were analyzed
+	tree       string            // What it was parsed to, for debugging
+	lines      []int             // The line each local module is imported on
+}
+
+// Finds the local modules a program imports, which are .py files next to the
+// entry file, or in a project's source directories
+type Loader struct {
+	dirs    []string
+	modules []Module              // Modules that have been loaded, in the order they have to run
+	stack   []string              // The modules being ordered, to find cycles
+	cache   map[string]CacheEntry // The last build, if it is kept
+	salt    string                // The compiler and settings, which are part of each key
+}
+
+// Lexes and parses a module. Nothing is shared with other modules, so modules
+// can be parsed at the same time
+func (m *Module) parse() error {
+	lexer := Lexer{}
+	lexSource, err := lexer.lex(m.input)
+	if err != nil {
+		return err
+	}
+
+	parser := Parser{}
+	ast, err := parser.parse(parser.replaceIndents(lexSource))
+	if err != nil {
+		return err
+	}
+	ast, m.functions = hoistDefinitions(ast)
+	m.tree = ast.stringify()
+
+	m.imports = []Structure{}
+	m.body = []Structure{}
+	m.defines = map[string]bool{}
+	m.variables = map[string]string{}
+	m.visible = map[string]string{}
+	m.prefixes = map[string]string{}
+	for i := 0; i < len(ast.children); i++ {
+		if ast.children[i].code == structureCode["ST_IMPORT"] {
+			m.imports = append(m.imports, ast.children[i])
+		} else {
+			m.body = append(m.body, ast.children[i])
+		}
+		if ast.children[i].code == structureCode["ST_DECLARATION"] {
+			m.variables[ast.children[i].children[0].text] = ast.children[i].children[2].text
+		}
+	}
+	for i := 0; i < len(m.functions); i++ {
+		m.defines[m.functions[i].children[1].text] = true
+	}
+	return nil
+}
+
+// The name something the module defines has in Go
+func (m *Module) goName(name string) string {
+	if m.entry {
+		return name
+	}
+	return strings.ReplaceAll(m.name, ".", "_") + "_" + name
+}
+
+// The file of the module, for errors, as each module counts its own lines
+func (m *Module) file() string {
+	return strings.ReplaceAll(m.name, ".", "/") + ".py"
+}
+
+// Prints what the modules that were parsed were parsed to
+func printTrees(modules []Module) {
+	for i := 0; i < len(modules); i++ {
+		if !modules[i].clean {
+			fmt.Println(modules[i].tree)
+		}
+	}
+}
+
+// Takes the imports of local modules out of a parsed module, keeping the names
+// it can use from them. Imports of Python modules and Go packages stay
+func (m *Module) link(modules []Module) error {
+	imports := []Structure{}
+	for i := 0; i < len(m.imports); i++ {
+		s := m.imports[i]
+		imported, local := findModule(s.children[1].text, modules)
+		if !local {
+			imports = append(imports, s)
+			continue
+		}
+
+		switch {
+		case s.children[0].code == structureCode["K_IMPORT"] && len(s.children) == 4:
+			m.prefixes[s.children[3].text] = imported.name
+		case s.children[0].code == structureCode["K_IMPORT"]:
+			m.prefixes[imported.name] = imported.name
+		case s.children[3].code == structureCode["ASTERISK"]:
+			names := imported.names()
+			for j := 0; j < len(names); j++ {
+				err := m.see(names[j], imported.name, s.line)
+				if err != nil {
+					return err
+				}
+			}
+		case !imported.has(s.children[3].text):
+			return createError([]string{"program.go", "link"}, "Cannot import \""+s.children[3].text+"\" from \""+imported.name+"\"", s.line)
+		default:
+			err := m.see(s.children[3].text, imported.name, s.line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	m.imports = imports
+	return nil
+}
+
+// Lets the module use a name imported from another. Python would let a later
+// import or definition of the name replace it, but Go needs one name for each
+func (m *Module) see(name string, from string, line int) error {
+	if owner, exists := m.visible[name]; exists && owner != from {
+		return createError([]string{"program.go", "see"}, "\""+name+"\" is imported from both "+owner+" and "+from, line)
+	}
+	if m.has(name) {
+		return createError([]string{"program.go", "see"}, "\""+name+"\" is imported from "+from+", so it can't be defined here too", line)
+	}
+	m.visible[name] = from
+	return nil
+}
+
+// Whether the module defines a function, class or module level variable
+func (m *Module) has(name string) bool {
+	_, variable := m.variables[name]
+	return m.defines[name] || variable
+}
+
+// The functions, classes and module level variables of the module, in order
+func (m *Module) names() []string {
+	names := []string{}
+	for name := range m.defines {
+		names = append(names, name)
+	}
+	for name := range m.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func findModule(name string, modules []Module) (Module, bool) {
+	for i := 0; i < len(modules); i++ {
+		if modules[i].name == name {
+			return modules[i], true
+		}
+	}
+	return Module{}, false
+}
+
+// Reads the entry file, given without .py, and every local module it needs
+func loadProgram(fileName string) ([]Module, error) {
+	return loadModules(filepath.Base(fileName), []string{filepath.Dir(fileName)}, nil, "")
+}
+
+// Reads a module and the local modules it needs from the directories they
+// can be in. Imported modules come before the modules that import them.
+// Modules the cache has kept for the same source and salt are clean. Each
+// wave of imports is read and parsed in parallel, and the modules are then
+// ordered and checked one at a time, so the output is always the same
+func loadModules(name string, dirs []string, cache map[string]CacheEntry, salt string) ([]Module, error) {
+	loader := Loader{dirs: dirs, cache: cache, salt: salt}
+	found := map[string]Module{}
+	seen := map[string]bool{name: true}
+	wave := []string{name}
+	for len(wave) > 0 {
+		modules := make([]Module, len(wave))
+		errs := make([]error, len(wave))
+		parallel(len(wave), func(i int) {
+			modules[i], errs[i] = loader.read(wave[i])
+		})
+
+		next := []string{}
+		for i := 0; i < len(wave); i++ {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			found[wave[i]] = modules[i]
+			for j := 0; j < len(modules[i].locals); j++ {
+				if !seen[modules[i].locals[j]] {
+					seen[modules[i].locals[j]] = true
+					next = append(next, modules[i].locals[j])
+				}
+			}
+		}
+		wave = next
+	}
+
+	err := loader.order(name, 0, found)
+	if err != nil {
+		return nil, err
+	}
+	loader.modules[len(loader.modules)-1].entry = true
+	for i := 0; i < len(loader.modules); i++ {
+		if loader.modules[i].clean {
+			continue
+		}
+		err = loader.modules[i].link(loader.modules)
+		if err != nil {
+			return nil, fileError(err, loader.modules[i].file())
+		}
+	}
+
+	err = checkDefinitions(loader.modules)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(loader.modules); i++ {
+		if loader.modules[i].clean {
+			continue
+		}
+		err = loader.modules[i].resolve(loader.modules)
+		if err != nil {
+			return nil, fileError(err, loader.modules[i].file())
+		}
+	}
+	return loader.modules, nil
+}
+
+// Where a module's file is, in the first directory that has it
+func (l *Loader) path(name string) string {
+	file := strings.ReplaceAll(name, ".", string(filepath.Separator)) + ".py"
+	for i := 0; i < len(l.dirs); i++ {
+		if _, err := os.Stat(filepath.Join(l.dirs[i], file)); err == nil {
+			return filepath.Join(l.dirs[i], file)
+		}
+	}
+	return filepath.Join(l.dirs[0], file)
+}
+
+// The local module an import refers to, or "" for Python modules and Go
+// packages
+func (l *Loader) local(s Structure) string {
+	name := s.children[1].text
+	if name == "GoType" || strings.HasPrefix(name, "GoType.") || isGoModule(name) {
+		return ""
+	}
+	if _, err := os.Stat(l.path(name)); err != nil {
+		return ""
+	}
+	return name
+}
+
+// Reads a module, only parsing it if the cache doesn't have it
+func (l *Loader) read(name string) (Module, error) {
+	input, err := os.ReadFile(l.path(name))
+	if err != nil {
+		return Module{}, createError([]string{"program.go", "read"}, "Couldn't read \""+l.path(name)+"\"", 0)
+	}
+	module := Module{name: name, input: input}
+
+	if l.cache != nil {
+		module.key = cacheKey(input, l.salt)
+	}
+	if entry, exists := l.cache[name]; exists {
+		module.cached = &entry
+		module.clean = entry.Key == module.key
+	}
+	if module.clean {
+		module.locals = module.cached.Locals
+		module.lines = make([]int, len(module.locals))
+		module.defines = map[string]bool{}
+		for i := 0; i < len(module.cached.Defines); i++ {
+			module.defines[module.cached.Defines[i]] = true
+		}
+		module.variables = module.cached.Variables
+		return module, nil
+	}
+
+	err = module.parse()
+	if err != nil {
+		return module, fileError(err, module.file())
+	}
+	for i := 0; i < len(module.imports); i++ {
+		if local := l.local(module.imports[i]); local != "" {
+			module.locals = append(module.locals, local)
+			module.lines = append(module.lines, module.imports[i].line)
+		}
+	}
+	return module, nil
+}
+
+// Puts a module after the modules it imports
+func (l *Loader) order(name string, line int, found map[string]Module) error {
+	for i := 0; i < len(l.stack); i++ {
+		if l.stack[i] == name {
+			importer := found[l.stack[len(l.stack)-1]]
+			return fileError(createError([]string{"program.go", "order"}, "Import cycle: "+strings.Join(append(l.stack[i:], name), " -> "), line), importer.file())
+		}
+	}
+	if _, ordered := findModule(name, l.modules); ordered {
+		return nil
+	}
+
+	module := found[name]
+	l.stack = append(l.stack, name)
+	for i := 0; i < len(module.locals); i++ {
+		err := l.order(module.locals[i], module.lines[i], found)
+		if err != nil {
+			return err
+		}
+	}
+	l.stack = l.stack[:len(l.stack)-1]
+	l.modules = append(l.modules, module)
+	return nil
+}
+
+// Go names can only be defined once, as every module ends up in one Go
+// package. Names are given their module's name, so only a name like
+// utils_helper in the entry file can be defined twice
+func checkDefinitions(modules []Module) error {
+	owners := map[string]string{}
+	for i := 0; i < len(modules); i++ {
+		names := modules[i].names()
+		for j := 0; j < len(names); j++ {
+			name := modules[i].goName(names[j])
+			if owner, exists := owners[name]; exists && owner != modules[i].name {
+				return createError([]string{"program.go", "checkDefinitions"}, "\""+name+"\" is defined in both "+owner+" and "+modules[i].name, 0)
+			}
+			owners[name] = modules[i].name
+		}
+	}
+	return nil
+}
+
+// Gives the names a module uses their names in Go, and turns uses of an
+// imported module, like utils.helper() and utils.LIMIT, into the names they
+// refer to. Calls to other modules' functions have to be imported
+func (m *Module) resolve(modules []Module) error {
+	owners := map[string]string{}
+	for i := len(modules) - 1; i >= 0; i-- {
+		for name := range modules[i].defines {
+			owners[name] = modules[i].name
+		}
+	}
+
+	// As in Python, a variable imported with from is the module's own, given
+	// the value it has once the module it is from has run
+	r := Resolver{m, map[string]string{}, map[string]string{}, owners}
+	copies := []Structure{}
+	visible := []string{}
+	for name := range m.visible {
+		visible = append(visible, name)
+	}
+	sort.Strings(visible)
+	for i := 0; i < len(visible); i++ {
+		imported, _ := findModule(m.visible[visible[i]], modules)
+		r.names[visible[i]] = imported.goName(visible[i])
+		if t, variable := imported.variables[visible[i]]; variable {
+			m.variables[visible[i]] = t
+			copies = append(copies, m.copyVariable(visible[i], t, imported))
+		}
+	}
+	names := m.names()
+	for i := 0; i < len(names); i++ {
+		r.names[names[i]] = m.goName(names[i])
+	}
+	for prefix, name := range m.prefixes {
+		imported, _ := findModule(name, modules)
+		names := imported.names()
+		for i := 0; i < len(names); i++ {
+			r.prefixed[prefix+"."+names[i]] = imported.goName(names[i])
+		}
+	}
+
+	for i := 0; i < len(m.functions); i++ {
+		definition := &m.functions[i].children[1]
+		definition.text = r.names[definition.text]
+		err := r.resolve(&m.functions[i], map[string]bool{})
+		if err != nil {
+			return err
+		}
+	}
+	// The types of module level variables are kept in Go, for the modules
+	// that import them
+	for i := 0; i < len(m.body); i++ {
+		s := &m.body[i]
+		name := ""
+		if s.code == structureCode["ST_DECLARATION"] {
+			name = s.children[0].text
+		}
+		err := r.resolve(s, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			m.variables[name] = s.children[2].text
+		}
+	}
+	m.body = append(copies, m.body...)
+	return nil
+}
+
+// The declaration of a variable imported from another module, which copies
+// its value
+func (m *Module) copyVariable(name string, t string, from Module) Structure {
+	line := 0
+	for i := 0; i < len(m.locals); i++ {
+		if m.locals[i] == from.name {
+			line = m.lines[i]
+			break
+		}
+	}
+	value := createStructure("EXPRESSION", "EXPRESSION", line)
+	value.children = append(value.children, createStructure("IDENTIFIER", from.goName(name), line))
+	declaration := createStructure("ST_DECLARATION", "ST_DECLARATION", line)
+	declaration.children = append(declaration.children,
+		createStructure("IDENTIFIER", m.goName(name), line),
+		createStructure("COLON", ":", line),
+		createStructure("IDENTIFIER", t, line),
+		createStructure("ASSIGN", "=", line),
+		value,
+	)
+	return declaration
+}
+
+// Renames what a module refers to, to the names they have in Go
+type Resolver struct {
+	module   *Module
+	names    map[string]string // The names the module can use, and their Go names
+	prefixed map[string]string // Names used through an imported module, like utils.helper
+	owners   map[string]string // The module each function and class is defined in
+}
+
+// Resolves the names in a structure. locals are the names the function it is
+// in binds, which aren't the module's
+func (r *Resolver) resolve(s *Structure, locals map[string]bool) error {
+	switch s.code {
+	case structureCode["ST_FUNCTION"], structureCode["LAMBDA"]:
+		locals = boundNames(*s, locals)
+	case structureCode["ST_CLASS"]:
+		return r.resolveClass(s, locals)
+	case structureCode["ST_GLOBAL"]:
+		for i := 1; i < len(s.children); i += 2 {
+			if _, own := r.module.variables[s.children[i].text]; own {
+				s.children[i].text = r.names[s.children[i].text]
+			}
+		}
+		return nil
+	case structureCode["ST_MANIPULATION"]:
+		target := s.children[0]
+		if target.code == structureCode["ATTRIBUTE"] && r.member(target, locals) == len(target.children)-1 {
+			return createError([]string{"program.go", "resolve"}, "A variable of another module can only be assigned to in its own module", s.line)
+		}
+	case structureCode["ST_CALL"]:
+		if s.children[0].code == structureCode["FUNC_NAME"] && !locals[s.children[0].text] {
+			name := s.children[0].text
+			if goName, exists := r.names[name]; exists {
+				s.children[0].text = goName
+			} else if owner, exists := r.owners[name]; exists && owner != r.module.name {
+				return createError([]string{"program.go", "resolve"}, "\""+name+"\" is defined in "+owner+", and has to be imported from it", s.line)
+			}
+		}
+		if s.children[0].code == structureCode["ATTRIBUTE"] {
+			err := r.resolveAttribute(&s.children[0], "FUNC_NAME", locals)
+			if err != nil {
+				return err
+			}
+		}
+	case structureCode["ATTRIBUTE"]:
+		err := r.resolveAttribute(s, "IDENTIFIER", locals)
+		if err != nil || s.code != structureCode["ATTRIBUTE"] {
+			return err
+		}
+		// Only the object is a name, not its attributes
+		return r.resolve(&s.children[0], locals)
+	case structureCode["KEYWORD_ARG"]:
+		return r.resolve(&s.children[2], locals)
+	case structureCode["IDENTIFIER"]:
+		if !locals[s.text] {
+			s.text = r.typeNames(s.text)
+		}
+		return nil
+	}
+
+	for i := 0; i < len(s.children); i++ {
+		err := r.resolve(&s.children[i], locals)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The fields of a class are its own names, so only their types and values
+// are resolved
+func (r *Resolver) resolveClass(s *Structure, locals map[string]bool) error {
+	s.children[1].text = r.typeNames(s.children[1].text)
+	body := &s.children[len(s.children)-1]
+	for i := 0; i < len(body.children); i++ {
+		field := &body.children[i]
+		if field.code != structureCode["ST_DECLARATION"] {
+			err := r.resolve(field, locals)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for j := 2; j < len(field.children); j += 2 {
+			err := r.resolve(&field.children[j], locals)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Where the member of an imported module is in an attribute like
+// utils.helper, or -1 when it doesn't start with an imported module
+func (r *Resolver) member(s Structure, locals map[string]bool) int {
+	if locals[s.children[0].text] {
+		return -1
+	}
+	prefix := ""
+	for i := 0; i+2 < len(s.children); i += 2 {
+		prefix += s.children[i].text
+		if _, exists := r.module.prefixes[prefix]; exists {
+			return i + 2
+		}
+		prefix += "."
+	}
+	return -1
+}
+
+// Turns a use of an imported module's name, like utils.helper, into its Go
+// name, which is given code when nothing comes after it
+func (r *Resolver) resolveAttribute(s *Structure, code string, locals map[string]bool) error {
+	i := r.member(*s, locals)
+	if i == -1 {
+		return nil
+	}
+	name := ""
+	for j := 0; j <= i; j++ {
+		name += s.children[j].text
+	}
+	goName, exists := r.prefixed[name]
+	if !exists {
+		return createError([]string{"program.go", "resolveAttribute"}, "\""+r.module.prefixes[name[:strings.LastIndex(name, ".")]]+"\" has no function, class or variable \""+s.children[i].text+"\"", s.line)
+	}
+	if i == len(s.children)-1 {
+		*s = createStructure(code, goName, s.line)
+		return nil
+	}
+	s.children = append([]Structure{createStructure("IDENTIFIER", goName, s.line)}, s.children[i+1:]...)
+	return nil
+}
+
+// Renames the module's names in a name, or in a type such as list[Box] or
+// utils.Box
+func (r *Resolver) typeNames(t string) string {
+	output := ""
+	start := 0
+	for i := 0; i <= len(t); i++ {
+		if i < len(t) && (isWordByte(t[i]) || t[i] == '.') {
+			continue
+		}
+		word := t[start:i]
+		if goName, exists := r.names[word]; exists {
+			word = goName
+		} else if goName, exists := r.prefixed[word]; exists {
+			word = goName
+		}
+		output += word
+		if i < len(t) {
+			output += t[i : i+1]
+		}
+		start = i + 1
+	}
+	return output
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// The names a function or lambda binds, as well as those around it. As in
+// Python, a name it assigns to anywhere is its own, unless it is global
+func boundNames(s Structure, outer map[string]bool) map[string]bool {
+	bound := map[string]bool{}
+	for name := range outer {
+		bound[name] = true
+	}
+	globals := map[string]bool{}
+	for i := 0; i < len(s.children); i++ {
+		child := s.children[i]
+		if s.code == structureCode["LAMBDA"] && child.code == structureCode["IDENTIFIER"] {
+			bound[child.text] = true
+		}
+		if child.code == structureCode["PARAMETER"] {
+			bound[child.children[0].text] = true
+		}
+		if child.code == structureCode["BLOCK"] {
+			bindings(child, bound, globals)
+		}
+	}
+	for name := range globals {
+		delete(bound, name)
+	}
+	return bound
+}
+
+func bindings(s Structure, bound map[string]bool, globals map[string]bool) {
+	for i := 0; i < len(s.children); i++ {
+		child := s.children[i]
+		switch child.code {
+		case structureCode["ST_FUNCTION"]:
+			bound[child.children[1].text] = true
+			continue
+		case structureCode["ST_CLASS"]:
+			bound[child.children[1].text] = true
+			continue
+		case structureCode["LAMBDA"]:
+			continue
+		case structureCode["ST_GLOBAL"]:
+			for j := 1; j < len(child.children); j += 2 {
+				globals[child.children[j].text] = true
+			}
+		case structureCode["ST_DECLARATION"], structureCode["ST_MANIPULATION"]:
+			if child.children[0].code == structureCode["IDENTIFIER"] {
+				bound[child.children[0].text] = true
+			}
+		case structureCode["ST_FOR"], structureCode["ST_UNPACK"]:
+			for j := 0; j < len(child.children); j++ {
+				if child.children[j].code == structureCode["IDENTIFIER"] {
+					bound[child.children[j].text] = true
+				}
+			}
+		case structureCode["WITH_ITEM"], structureCode["ST_EXCEPT"]:
+			for j := 1; j < len(child.children); j++ {
+				if child.children[j-1].code == structureCode["K_AS"] {
+					bound[child.children[j].text] = true
+				}
+			}
+		}
+		bindings(child, bound, globals)
+	}
+}
+
+// The top level code of a module, as a function. The entry file's is main,
+// which calls the others' first, in the order they were imported
+func topLevelFunction(name string, body []Structure) Structure {
+	return Structure{
+		structureCode["ST_FUNCTION"],
+		"ST_FUNCTION",
+		-1,
+		[]Structure{
+			createStructure("K_DEF", "def", -1),
+			createStructure("FUNC_NAME", name, -1),
+			createStructure("L_PAREN", "(", -1),
+			createStructure("R_PAREN", ")", -1),
+			createStructure("COLON", ":", -1),
+			{structureCode["BLOCK"], "", -1, append(body, createStructure("ANTI_COLON", ":", -1)), ""},
+		},
+		"",
+	}
+}
+
+// Whether a module's top level code does anything
+func hasCode(body []Structure) bool {
+	for i := 0; i < len(body); i++ {
+		switch body[i].code {
+		case structureCode["NEWLINE"], structureCode["COMMENT_ONE"], structureCode["COMMENT_MULTI"]:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// The function an imported module's top level code is in
+func initName(module string) string {
+	return "pyInit_" + strings.ReplaceAll(module, ".", "_")
+}
+
+func initCall(name string) Structure {
+	call := createStructure("ST_CALL", "ST_CALL", -1)
+	call.children = append(call.children,
+		createStructure("FUNC_NAME", name, -1),
+		createStructure("L_PAREN", "(", -1),
+		createStructure("R_PAREN", ")", -1),
+	)
+	return call
+}
+
+// Whether a module has top level code to run
+func (m *Module) runs() bool {
+	if m.clean {
+		return m.cached.HasCode
+	}
+	return hasCode(m.body)
+}
+
+// Adds what a clean module declares, from the cache, for the modules being
+// compiled. known is given whether each of its functions raises
+func (m *Module) declareCached(a *Analyzer, funcs *[]Function, known map[string]bool) error {
+	for i := 0; i < len(m.cached.Signatures); i++ {
+		f, err := a.declare(m.cached.Signatures[i].structure())
+		if err != nil {
+			return err
+		}
+		*funcs = append(*funcs, f)
+	}
+	if m.cached.HasCode {
+		*funcs = append(*funcs, Function{name: initName(m.name), params: []string{}, varType: "None"})
+	}
+	for i := 0; i < len(m.cached.Defines); i++ {
+		known[m.goName(m.cached.Defines[i])] = false
+	}
+	for i := 0; i < len(m.cached.Raising); i++ {
+		known[m.goName(m.cached.Raising[i])] = true
+	}
+	return nil
+}
+
+// The module level variables of a module, which are Go package variables, so
+// functions can read them without global, and the modules that import them can
+// use them
+func (m *Module) packageVariables() []Variable {
+	names := []string{}
+	for name := range m.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	vars := []Variable{}
+	for i := 0; i < len(names); i++ {
+		vars = append(vars, Variable{m.goName(names[i]), m.variables[names[i]]})
+	}
+	return vars
+}
+
+// Parses the modules that aren't clean again, as analyzing them changes
+// their structures
+func reload(modules []Module) error {
+	errs := make([]error, len(modules))
+	parallel(len(modules), func(i int) {
+		if !modules[i].clean {
+			errs[i] = modules[i].parse()
+		}
+	})
+
+	for i := 0; i < len(modules); i++ {
+		if modules[i].clean {
+			continue
+		}
+		if errs[i] != nil {
+			return fileError(errs[i], modules[i].file())
+		}
+		err := modules[i].link(modules)
+		if err == nil {
+			err = modules[i].resolve(modules)
+		}
+		if err != nil {
+			return fileError(err, modules[i].file())
+		}
+	}
+	return nil
+}
+
+// Runs work for each number up to n on a pool of workers, one for each CPU
+func parallel(n int, work func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU() && i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				work(job)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// Whether a module imports one of the modules given. The entry file depends on
+// every module, as it runs their top level code
+func (m *Module) importsAny(modules map[string]bool, entry bool) bool {
+	if entry {
+		return len(modules) > 0
+	}
+	for i := 0; i < len(m.locals); i++ {
+		if modules[m.locals[i]] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"go/format"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The pogo.toml of a project, which pogo build turns into a Go module
+type Manifest struct {
+	module    string   // The Go module path
+	entry     string   // The Python file the program starts in
+	goVersion string   // The Go version written to go.mod
+	sources   []string // Directories local modules are found in, after the entry file's
+	output    string   // The directory the Go module is written to
+}
+
+// Generated files start with this, and are the only files pogo build replaces
+const generatedHeader = "// Code generated by Pogo. DO NOT EDIT."
+
+// Reads a manifest. It is a small part of TOML: keys with string or string
+// array values, optionally in a [project] table
+func readManifest(path string) (Manifest, error) {
+	version := strings.TrimPrefix(runtime.Version(), "go")
+	if parts := strings.Split(version, "."); len(parts) > 2 {
+		version = parts[0] + "." + parts[1]
+	}
+	manifest := Manifest{entry: "main.py", goVersion: version, output: "build"}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return manifest, createError([]string{"project.go", "readManifest"}, "Couldn't read \""+path+"\"", 0)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || text == "[project]" {
+			continue
+		}
+
+		key, value, found := strings.Cut(text, "=")
+		if !found {
+			return manifest, createError([]string{"project.go", "readManifest"}, "Expected key = value in \""+path+"\"", line)
+		}
+		key = strings.TrimSpace(key)
+		values, err := manifestValue(strings.TrimSpace(value))
+		if err != nil {
+			return manifest, createError([]string{"project.go", "readManifest"}, "Bad value for \""+key+"\" in \""+path+"\", "+err.Error(), line)
+		}
+
+		if key == "sources" {
+			manifest.sources = values
+			continue
+		}
+		if len(values) != 1 {
+			return manifest, createError([]string{"project.go", "readManifest"}, "\""+key+"\" takes one string in \""+path+"\"", line)
+		}
+		switch key {
+		case "module":
+			manifest.module = values[0]
+		case "entry":
+			manifest.entry = values[0]
+		case "go":
+			manifest.goVersion = values[0]
+		case "output":
+			manifest.output = values[0]
+		default:
+			return manifest, createError([]string{"project.go", "readManifest"}, "Unknown key \""+key+"\" in \""+path+"\"", line)
+		}
+	}
+
+	if manifest.module == "" {
+		return manifest, createError([]string{"project.go", "readManifest"}, "\""+path+"\" doesn't give a module", 0)
+	}
+	return manifest, nil
+}
+
+// A string, or an array of strings on one line, followed by an optional
+// comment
+func manifestValue(value string) ([]string, error) {
+	array := strings.HasPrefix(value, "[")
+	if array {
+		value = strings.TrimSpace(value[1:])
+	}
+
+	values := []string{}
+	for {
+		if array && strings.HasPrefix(value, "]") {
+			value = strings.TrimSpace(value[1:])
+			break
+		}
+		quoted, err := strconv.QuotedPrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		text, _ := strconv.Unquote(quoted)
+		values = append(values, text)
+		value = strings.TrimSpace(value[len(quoted):])
+		if !array {
+			break
+		}
+		value = strings.TrimSpace(strings.TrimPrefix(value, ","))
+	}
+
+	if value != "" && !strings.HasPrefix(value, "#") {
+		return nil, strconv.ErrSyntax
+	}
+	return values, nil
+}
+
+// Transpiles a project to a Go module, with a file for each Python module and
+// one for the runtime helpers. The modules that were compiled, rather than
+// taken from the cache, are returned. With trees, what they were parsed to is
+// printed
+func buildProject(dir string, settings Settings, trees bool) ([]string, error) {
+	manifest, err := readManifest(filepath.Join(dir, "pogo.toml"))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := filepath.Join(dir, manifest.entry)
+	dirs := []string{filepath.Dir(entry)}
+	for i := 0; i < len(manifest.sources); i++ {
+		dirs = append(dirs, filepath.Join(dir, manifest.sources[i]))
+	}
+	cacheDir := filepath.Join(dir, ".pogo-cache")
+	modules, err := loadModules(strings.TrimSuffix(filepath.Base(entry), ".py"), dirs, readCache(cacheDir), cacheSalt(settings))
+	if err != nil {
+		return nil, err
+	}
+	if trees {
+		printTrees(modules)
+	}
+
+	// Clean modules that import a module whose signatures changed are
+	// compiled again, with the modules that were being compiled, as analyzing
+	// changes the structures
+	files, entries, err := compileFiles(modules, settings)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		changed := map[string]bool{}
+		for i := 0; i < len(modules); i++ {
+			if !modules[i].clean && (modules[i].cached == nil || !sameSignatures(*modules[i].cached, entries[i])) {
+				changed[modules[i].name] = true
+			}
+		}
+
+		stale := false
+		for i := 0; i < len(modules); i++ {
+			if modules[i].clean && modules[i].importsAny(changed, i == len(modules)-1) {
+				modules[i].clean = false
+				stale = true
+			}
+		}
+		if !stale {
+			break
+		}
+		err := reload(modules)
+		if err != nil {
+			return nil, err
+		}
+		if trees {
+			printTrees(modules)
+		}
+		files, entries, err = compileFiles(modules, settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files["go.mod"] = generatedHeader + "\n\nmodule " + manifest.module + "\n\ngo " + manifest.goVersion + "\n"
+	err = writeModule(filepath.Join(dir, manifest.output), files)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := []string{}
+	for i := 0; i < len(modules); i++ {
+		if !modules[i].clean {
+			compiled = append(compiled, modules[i].name)
+		}
+	}
+	return compiled, writeCache(cacheDir, modules, entries)
+}
+
+// The Go source of each module of a program, by file name, and what the cache
+// keeps of each module. Clean modules have the Go they were cached with. The
+// modules are analyzed together, then emitted in parallel, each with its own
+// emitter
+func compileFiles(modules []Module, settings Settings) (map[string]string, []CacheEntry, error) {
+	ast, indexes, analyzer, err := analyzeProgram(modules, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emitters := make([]Emitter, len(modules))
+	sources := make([]string, len(modules))
+	errs := make([]error, len(modules))
+	parallel(len(modules), func(i int) {
+		if modules[i].clean {
+			return
+		}
+		emitters[i] = Emitter{raising: analyzer.raising, classes: analyzer.classes, packages: analyzer.packages, settings: settings}
+		file := Structure{structureCode["PROGRAM"], "PROGRAM", 0, []Structure{}, ""}
+		for j := 0; j < len(indexes[i]); j++ {
+			file.children = append(file.children, ast.children[indexes[i][j]])
+		}
+		sources[i], errs[i] = emitters[i].emit(file)
+	})
+
+	files := map[string]string{}
+	entries := []CacheEntry{}
+	helpers := Emitter{}
+	for i := 0; i < len(modules); i++ {
+		name := goFileName(modules[i].name)
+		if modules[i].clean {
+			files[name] = modules[i].cached.Source
+			entries = append(entries, *modules[i].cached)
+			for j := 0; j < len(modules[i].cached.Helpers); j++ {
+				helpers.helper(modules[i].cached.Helpers[j])
+			}
+			continue
+		}
+
+		if errs[i] != nil {
+			return nil, nil, fileError(errs[i], modules[i].file())
+		}
+		emitter := emitters[i]
+		files[name] = generatedHeader + "\n\npackage main\n" + importBlock(emitter.imports) + sources[i] + "\n"
+		for j := 0; j < len(emitter.helpers); j++ {
+			helpers.helper(emitter.helpers[j])
+		}
+
+		entry := CacheEntry{Key: modules[i].key, Locals: modules[i].locals, Defines: []string{}, Variables: modules[i].variables, Signatures: modules[i].signatures, Raising: []string{}, HasCode: hasCode(modules[i].body), Helpers: emitter.helpers, Source: files[name]}
+		for name := range modules[i].defines {
+			entry.Defines = append(entry.Defines, name)
+			if analyzer.raising[modules[i].goName(name)] {
+				entry.Raising = append(entry.Raising, name)
+			}
+		}
+		sort.Strings(entry.Defines)
+		sort.Strings(entry.Raising)
+		entries = append(entries, entry)
+	}
+	files["pogo_runtime.go"] = generatedHeader + "\n\npackage main\n" + importBlock(helpers.helperImports()) + helpers.helperSource()
+	return files, entries, nil
+}
+
+// The Go file a module is written to. It ends in _py.go, so a module such as
+// calc_test or io_windows isn't taken by Go for a test or a file of one OS,
+// and none is the same as pogo_runtime.go
+func goFileName(module string) string {
+	return strings.ReplaceAll(module, ".", "_") + "_py.go"
+}
+
+// Writes the files of a Go module, removing Go files left by earlier builds
+// for modules that are gone
+func writeModule(dir string, files map[string]string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return createError([]string{"project.go", "writeModule"}, err.Error(), 0)
+	}
+
+	old, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for i := 0; i < len(old); i++ {
+		if _, exists := files[filepath.Base(old[i])]; exists {
+			continue
+		}
+		source, err := os.ReadFile(old[i])
+		if err == nil && strings.HasPrefix(string(source), generatedHeader) {
+			os.Remove(old[i])
+		}
+	}
+
+	// Go files are formatted, unless they don't parse, so the error shows
+	// when they are built. A file that exists without the header was written
+	// by hand, such as a go.mod given its own requirements, and is kept
+	for name, source := range files {
+		existing, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil && !strings.HasPrefix(string(existing), generatedHeader) {
+			continue
+		}
+		output := []byte(source)
+		if formatted, err := format.Source(output); err == nil && strings.HasSuffix(name, ".go") {
+			output = formatted
+		}
+		err = os.WriteFile(filepath.Join(dir, name), output, 0644)
+		if err != nil {
+			return createError([]string{"project.go", "writeModule"}, err.Error(), 0)
+		}
+	}
+	return nil
+}
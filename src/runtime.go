@@ -0,0 +1,1168 @@
+package main
+
+import "sort"
+
+// Go source that is added to the output when the emitted code needs it
+type Helper struct {
+	imports []string // Go packages the helper uses
+	needs   []string // Other helpers the helper uses
+	source  string
+}
+
+// Marks a runtime helper, and everything it relies on, as needed by the
+// emitted code
+func (e *Emitter) helper(name string) {
+	for i := 0; i < len(e.helpers); i++ {
+		if e.helpers[i] == name {
+			return
+		}
+	}
+	e.helpers = append(e.helpers, name)
+
+	h := helperFor(name)
+	for i := 0; i < len(h.needs); i++ {
+		e.helper(h.needs[i])
+	}
+}
+
+// The source of every helper that was used, in a stable order
+func (e *Emitter) helperSource() string {
+	sorted := append([]string{}, e.helpers...)
+	sort.Strings(sorted)
+
+	output := ""
+	for i := 0; i < len(sorted); i++ {
+		output += "\n" + helperFor(sorted[i]).source
+	}
+	return output
+}
+
+// The Go packages the helpers that were used need
+func (e *Emitter) helperImports() []string {
+	imports := []string{}
+	for i := 0; i < len(e.helpers); i++ {
+		imports = append(imports, helperFor(e.helpers[i]).imports...)
+	}
+	return imports
+}
+
+func helperFor(name string) Helper {
+	_, exception := exceptionParents[name]
+	if exception {
+		return exceptionHelper(name)
+	}
+	return helpers[name]
+}
+
+// Python's built in exceptions, and the class each inherits from
+var exceptionParents map[string]string = map[string]string{
+	"Exception":           "",
+	"ArithmeticError":     "Exception",
+	"AssertionError":      "Exception",
+	"EOFError":            "Exception",
+	"FileNotFoundError":   "OSError",
+	"IndexError":          "LookupError",
+	"KeyError":            "LookupError",
+	"LookupError":         "Exception",
+	"NotImplementedError": "RuntimeError",
+	"OSError":             "Exception",
+	"OverflowError":       "ArithmeticError",
+	"RuntimeError":        "Exception",
+	"TypeError":           "Exception",
+	"ValueError":          "Exception",
+	"ZeroDivisionError":   "ArithmeticError",
+}
+
+// Exceptions become Go error types. Inheritance is modelled by unwrapping to
+// the parent class, so errors.As matches a class and everything under it
+func exceptionHelper(name string) Helper {
+	parent := exceptionParents[name]
+
+	source := "type " + name + " struct{ msg string }\n\n"
+	source += "func (e *" + name + ") Error() string { return e.msg }\n"
+	if parent == "" {
+		return Helper{[]string{}, []string{}, source}
+	}
+	source += "func (e *" + name + ") Unwrap() error { return &" + parent + "{e.msg} }\n"
+	return Helper{[]string{}, []string{parent}, source}
+}
+
+var helpers map[string]Helper = map[string]Helper{
+	// Exceptions that reach the top of the program
+	"pyTraceback": {
+		[]string{"fmt", "os", "reflect", "strings"},
+		[]string{},
+		`func pyTraceback(err error, line int) {
+	name := reflect.TypeOf(err).String()
+	name = name[strings.LastIndex(name, ".")+1:]
+	fmt.Fprintln(os.Stderr, "Traceback (most recent call last):")
+	fmt.Fprintf(os.Stderr, "  line %d, in <module>\n", line)
+	if err.Error() == "" {
+		fmt.Fprintln(os.Stderr, name)
+	} else {
+		fmt.Fprintln(os.Stderr, name+": "+err.Error())
+	}
+	os.Exit(1)
+}
+`,
+	},
+
+	// Python's open, with its errors as exceptions
+	"pyOpen": {
+		[]string{"errors", "io/fs", "os", "strings"},
+		[]string{"FileNotFoundError", "OSError", "ValueError"},
+		`func pyOpen(file, mode string) (*os.File, error) {
+	flags := map[string]int{
+		"r": os.O_RDONLY,
+		"w": os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+		"a": os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+		"x": os.O_WRONLY | os.O_CREATE | os.O_EXCL,
+	}
+	flag, valid := flags[strings.Trim(mode, "bt")]
+	if !valid {
+		return nil, &ValueError{"invalid mode: '" + mode + "'"}
+	}
+
+	f, err := os.OpenFile(file, flag, 0666)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &FileNotFoundError{"No such file or directory: '" + file + "'"}
+	}
+	if err != nil {
+		return nil, &OSError{err.Error()}
+	}
+	return f, nil
+}
+`,
+	},
+
+	"pyRead": {
+		[]string{"io", "os"},
+		[]string{},
+		`func pyRead(f *os.File) string {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+`,
+	},
+
+	// Returns how many characters were written, like Python
+	"pyWrite": {
+		[]string{"os", "unicode/utf8"},
+		[]string{},
+		`func pyWrite(f *os.File, s string) int {
+	n, err := f.WriteString(s)
+	if err != nil {
+		panic(err)
+	}
+	return utf8.RuneCountInString(s[:n])
+}
+`,
+	},
+
+	// Raises an int to a power, as Go only has math.Pow for floats
+	"pyIntPow": {
+		[]string{},
+		[]string{},
+		`func pyIntPow[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T) T {
+	if y < 0 {
+		panic("negative power of an int")
+	}
+	result := T(1)
+	for ; y > 0; y /= 2 {
+		if y%2 == 1 {
+			result *= x
+		}
+		x *= x
+	}
+	return result
+}
+`,
+	},
+
+	// Division that rounds down rather than towards zero, like Python's //
+	"pyFloorDiv": {
+		[]string{},
+		[]string{},
+		`func pyFloorDiv[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T) T {
+	q := x / y
+	if x%y != 0 && (x < 0) != (y < 0) {
+		q--
+	}
+	return q
+}
+`,
+	},
+
+	// A remainder with the sign of the divisor, like Python's %
+	"pyMod": {
+		[]string{},
+		[]string{},
+		`func pyMod[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T) T {
+	r := x % y
+	if r != 0 && (r < 0) != (y < 0) {
+		r += y
+	}
+	return r
+}
+`,
+	},
+
+	"pyFloatMod": {
+		[]string{"math"},
+		[]string{},
+		`func pyFloatMod(x, y float64) float64 {
+	r := math.Mod(x, y)
+	if r != 0 && (r < 0) != (y < 0) {
+		r += y
+	}
+	return r
+}
+`,
+	},
+
+	"pyDivmod": {
+		[]string{},
+		[]string{"pyFloorDiv", "pyMod"},
+		`func pyDivmod[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T) (T, T) {
+	return pyFloorDiv(x, y), pyMod(x, y)
+}
+`,
+	},
+
+	"pyFloatDivmod": {
+		[]string{"math"},
+		[]string{"pyFloatMod"},
+		`func pyFloatDivmod[T ~float32 | ~float64](x, y T) (T, T) {
+	return T(math.Floor(float64(x / y))), T(pyFloatMod(float64(x), float64(y)))
+}
+`,
+	},
+
+	// Big ints too big to be written as an int64 constant
+	"pyBigInt": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigInt(s string) *big.Int {
+	n, _ := new(big.Int).SetString(s, 10)
+	return n
+}
+`,
+	},
+
+	"pyBigFloat": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigFloat(x *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
+`,
+	},
+
+	// big.Int's DivMod is Euclidean, so Python's rounding is worked out from
+	// QuoRem, which rounds towards zero
+	"pyBigDivmod": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigDivmod(x, y *big.Int) (*big.Int, *big.Int) {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() != 0 && r.Sign() != y.Sign() {
+		q.Sub(q, big.NewInt(1))
+		r.Add(r, y)
+	}
+	return q, r
+}
+`,
+	},
+
+	"pyBigFloorDiv": {
+		[]string{"math/big"},
+		[]string{"pyBigDivmod"},
+		`func pyBigFloorDiv(x, y *big.Int) *big.Int {
+	q, _ := pyBigDivmod(x, y)
+	return q
+}
+`,
+	},
+
+	"pyBigMod": {
+		[]string{"math/big"},
+		[]string{"pyBigDivmod"},
+		`func pyBigMod(x, y *big.Int) *big.Int {
+	_, r := pyBigDivmod(x, y)
+	return r
+}
+`,
+	},
+
+	"pyBigPow": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigPow(x, y *big.Int) *big.Int {
+	if y.Sign() < 0 {
+		panic("negative power of an int")
+	}
+	return new(big.Int).Exp(x, y, nil)
+}
+`,
+	},
+
+	// Python's int() of a string, which raises a ValueError for a string that
+	// isn't an int
+	"pyAtoi": {
+		[]string{"strconv", "strings"},
+		[]string{"pyStr", "ValueError"},
+		`func pyAtoi(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, &ValueError{"invalid literal for int() with base 10: " + pyRepr(s)}
+	}
+	return n, nil
+}
+`,
+	},
+
+	"pyBigAtoi": {
+		[]string{"math/big", "strings"},
+		[]string{"pyStr", "ValueError"},
+		`func pyBigAtoi(s string) (*big.Int, error) {
+	n, valid := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !valid {
+		return nil, &ValueError{"invalid literal for int() with base 10: " + pyRepr(s)}
+	}
+	return n, nil
+}
+`,
+	},
+
+	"pyBigTrunc": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigTrunc(f float64) *big.Int {
+	n, _ := new(big.Float).SetFloat64(f).Int(nil)
+	return n
+}
+`,
+	},
+
+	// Arithmetic that panics with the Python line when it overflows, for
+	// -checked-arith
+	"pyOverflow": {
+		[]string{"fmt"},
+		[]string{},
+		`func pyOverflow(value any, line int) {
+	panic(fmt.Sprintf("OverflowError: %T overflowed on line %d", value, line))
+}
+`,
+	},
+
+	"pyCheckedAdd": {
+		[]string{},
+		[]string{"pyOverflow"},
+		`func pyCheckedAdd[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T, line int) T {
+	r := x + y
+	if y > 0 && r < x || y < 0 && r > x {
+		pyOverflow(r, line)
+	}
+	return r
+}
+`,
+	},
+
+	"pyCheckedSub": {
+		[]string{},
+		[]string{"pyOverflow"},
+		`func pyCheckedSub[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T, line int) T {
+	r := x - y
+	if y > 0 && r > x || y < 0 && r < x {
+		pyOverflow(r, line)
+	}
+	return r
+}
+`,
+	},
+
+	// Multiplying by ^T(0), which is -1 when T is signed, overflows without
+	// changing the result of dividing back
+	"pyCheckedMul": {
+		[]string{},
+		[]string{"pyOverflow"},
+		`func pyCheckedMul[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T, line int) T {
+	if x == 0 || y == 0 {
+		return 0
+	}
+	r := x * y
+	if r/y != x || y == ^T(0) && r == x {
+		pyOverflow(r, line)
+	}
+	return r
+}
+`,
+	},
+
+	"pyCheckedPow": {
+		[]string{},
+		[]string{"pyCheckedMul"},
+		`func pyCheckedPow[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T, line int) T {
+	if y < 0 {
+		panic("negative power of an int")
+	}
+	result := T(1)
+	for ; y > 0; y /= 2 {
+		if y%2 == 1 {
+			result = pyCheckedMul(result, x, line)
+		}
+		if y > 1 {
+			x = pyCheckedMul(x, x, line)
+		}
+	}
+	return result
+}
+`,
+	},
+
+	// The most negative int divided by -1 is the only division that
+	// overflows
+	"pyCheckedFloorDiv": {
+		[]string{},
+		[]string{"pyFloorDiv", "pyOverflow"},
+		`func pyCheckedFloorDiv[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x, y T, line int) T {
+	if y == ^T(0) && x < 0 && x == -x {
+		pyOverflow(x, line)
+	}
+	return pyFloorDiv(x, y)
+}
+`,
+	},
+
+	"pyCheckedShift": {
+		[]string{},
+		[]string{"pyOverflow"},
+		`func pyCheckedShift[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr, N ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x T, n N, line int) T {
+	r := x << n
+	if r>>n != x {
+		pyOverflow(r, line)
+	}
+	return r
+}
+`,
+	},
+
+	// A dict, which keeps its keys in the order they were added, as Python
+	// does and Go's maps don't
+	"pyDict": {
+		[]string{"strings"},
+		[]string{"pyStr"},
+		`type pyDict[K comparable, V any] struct {
+	keys []K
+	m    map[K]V
+}
+
+// Builds a dict from its keys and values, where a repeated key keeps its
+// first place and its last value
+func pyNewDict[K comparable, V any](keys []K, values []V) pyDict[K, V] {
+	d := pyDict[K, V]{m: make(map[K]V, len(keys))}
+	for i := 0; i < len(keys); i++ {
+		if _, exists := d.m[keys[i]]; !exists {
+			d.keys = append(d.keys, keys[i])
+		}
+		d.m[keys[i]] = values[i]
+	}
+	return d
+}
+
+func (d pyDict[K, V]) Len() int {
+	return len(d.keys)
+}
+
+func (d pyDict[K, V]) String() string {
+	items := make([]string, len(d.keys))
+	for i := 0; i < len(d.keys); i++ {
+		items[i] = pyRepr(d.keys[i]) + ": " + pyRepr(d.m[d.keys[i]])
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+`,
+	},
+
+	// A map from Go as a dict. Go's maps have no order to keep, so the keys
+	// are sorted to stay deterministic
+	"pyFromMap": {
+		[]string{"sort"},
+		[]string{"pyDict", "pyOrdered"},
+		`func pyFromMap[K pyOrdered, V any](m map[K]V) pyDict[K, V] {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return pyDict[K, V]{keys, m}
+}
+`,
+	},
+
+	// Python's float() of a string, which takes inf and nan as well
+	"pyAtof": {
+		[]string{"strconv", "strings"},
+		[]string{"pyStr", "ValueError"},
+		`func pyAtof(s string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, &ValueError{"could not convert string to float: " + pyRepr(s)}
+	}
+	return f, nil
+}
+`,
+	},
+
+	// The types min, max and sorted can compare
+	"pyOrdered": {
+		[]string{},
+		[]string{},
+		`type pyOrdered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr | ~float32 | ~float64 | ~string
+}
+`,
+	},
+
+	"pyAbs": {
+		[]string{},
+		[]string{},
+		`func pyAbs[T ~int | ~int8 | ~int16 | ~int32 | ~int64](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+`,
+	},
+
+	"pyMinOf": {
+		[]string{},
+		[]string{"pyMin", "ValueError"},
+		`func pyMinOf[T pyOrdered](xs []T) (T, error) {
+	if len(xs) == 0 {
+		var zero T
+		return zero, &ValueError{"min() arg is an empty sequence"}
+	}
+	return pyMin(xs...), nil
+}
+`,
+	},
+
+	"pyMin": {
+		[]string{},
+		[]string{"pyOrdered"},
+		`func pyMin[T pyOrdered](xs ...T) T {
+	result := xs[0]
+	for _, x := range xs[1:] {
+		if x < result {
+			result = x
+		}
+	}
+	return result
+}
+`,
+	},
+
+	"pyMaxOf": {
+		[]string{},
+		[]string{"pyMax", "ValueError"},
+		`func pyMaxOf[T pyOrdered](xs []T) (T, error) {
+	if len(xs) == 0 {
+		var zero T
+		return zero, &ValueError{"max() arg is an empty sequence"}
+	}
+	return pyMax(xs...), nil
+}
+`,
+	},
+
+	"pyMax": {
+		[]string{},
+		[]string{"pyOrdered"},
+		`func pyMax[T pyOrdered](xs ...T) T {
+	result := xs[0]
+	for _, x := range xs[1:] {
+		if x > result {
+			result = x
+		}
+	}
+	return result
+}
+`,
+	},
+
+	"pyBigMinOf": {
+		[]string{"math/big"},
+		[]string{"pyBigMin", "ValueError"},
+		`func pyBigMinOf(xs []*big.Int) (*big.Int, error) {
+	if len(xs) == 0 {
+		return nil, &ValueError{"min() arg is an empty sequence"}
+	}
+	return pyBigMin(xs...), nil
+}
+`,
+	},
+
+	"pyBigMin": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigMin(xs ...*big.Int) *big.Int {
+	result := xs[0]
+	for _, x := range xs[1:] {
+		if x.Cmp(result) < 0 {
+			result = x
+		}
+	}
+	return result
+}
+`,
+	},
+
+	"pyBigMaxOf": {
+		[]string{"math/big"},
+		[]string{"pyBigMax", "ValueError"},
+		`func pyBigMaxOf(xs []*big.Int) (*big.Int, error) {
+	if len(xs) == 0 {
+		return nil, &ValueError{"max() arg is an empty sequence"}
+	}
+	return pyBigMax(xs...), nil
+}
+`,
+	},
+
+	"pyBigMax": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigMax(xs ...*big.Int) *big.Int {
+	result := xs[0]
+	for _, x := range xs[1:] {
+		if x.Cmp(result) > 0 {
+			result = x
+		}
+	}
+	return result
+}
+`,
+	},
+
+	"pySum": {
+		[]string{},
+		[]string{},
+		`func pySum[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr | ~float32 | ~float64](xs []T) T {
+	var sum T
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+`,
+	},
+
+	"pyBigSum": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigSum(xs []*big.Int) *big.Int {
+	sum := new(big.Int)
+	for _, x := range xs {
+		sum.Add(sum, x)
+	}
+	return sum
+}
+`,
+	},
+
+	// A sorted copy, which keeps equal items in order like Python's sort
+	"pySorted": {
+		[]string{"sort"},
+		[]string{"pyOrdered"},
+		`func pySorted[T pyOrdered](xs []T, reverse bool) []T {
+	sorted := append([]T{}, xs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if reverse {
+			return sorted[i] > sorted[j]
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+`,
+	},
+
+	// A copy sorted by a key, which is worked out once for each item
+	"pySortedBy": {
+		[]string{"sort"},
+		[]string{"pyOrdered"},
+		`func pySortedBy[T any, K pyOrdered](xs []T, key func(T) K, reverse bool) []T {
+	keys := make([]K, len(xs))
+	order := make([]int, len(xs))
+	for i, x := range xs {
+		keys[i] = key(x)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if reverse {
+			return keys[order[i]] > keys[order[j]]
+		}
+		return keys[order[i]] < keys[order[j]]
+	})
+	sorted := make([]T, len(xs))
+	for i, j := range order {
+		sorted[i] = xs[j]
+	}
+	return sorted
+}
+`,
+	},
+
+	"pyBigSortedBy": {
+		[]string{"math/big", "sort"},
+		[]string{},
+		`func pyBigSortedBy[T any](xs []T, key func(T) *big.Int, reverse bool) []T {
+	keys := make([]*big.Int, len(xs))
+	order := make([]int, len(xs))
+	for i, x := range xs {
+		keys[i] = key(x)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if reverse {
+			return keys[order[i]].Cmp(keys[order[j]]) > 0
+		}
+		return keys[order[i]].Cmp(keys[order[j]]) < 0
+	})
+	sorted := make([]T, len(xs))
+	for i, j := range order {
+		sorted[i] = xs[j]
+	}
+	return sorted
+}
+`,
+	},
+
+	"pyBigSorted": {
+		[]string{"math/big", "sort"},
+		[]string{},
+		`func pyBigSorted(xs []*big.Int, reverse bool) []*big.Int {
+	sorted := append([]*big.Int{}, xs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if reverse {
+			return sorted[i].Cmp(sorted[j]) > 0
+		}
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+	return sorted
+}
+`,
+	},
+
+	// A reversed copy, as Go has no iterators to give back
+	"pyReversed": {
+		[]string{},
+		[]string{},
+		`func pyReversed[T any](xs []T) []T {
+	reversed := make([]T, len(xs))
+	for i, x := range xs {
+		reversed[len(xs)-1-i] = x
+	}
+	return reversed
+}
+`,
+	},
+
+	"pyAny": {
+		[]string{},
+		[]string{},
+		`func pyAny[T comparable](xs []T) bool {
+	var zero T
+	for _, x := range xs {
+		if x != zero {
+			return true
+		}
+	}
+	return false
+}
+`,
+	},
+
+	"pyAll": {
+		[]string{},
+		[]string{},
+		`func pyAll[T comparable](xs []T) bool {
+	var zero T
+	for _, x := range xs {
+		if x == zero {
+			return false
+		}
+	}
+	return true
+}
+`,
+	},
+
+	// Python's truthiness for a value of type any
+	"pyBool": {
+		[]string{"reflect"},
+		[]string{},
+		`func pyBool(v any) bool {
+	if v == nil {
+		return false
+	}
+	if sized, ok := v.(interface{ Len() int }); ok {
+		return sized.Len() > 0
+	}
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Slice, reflect.Map:
+		return r.Len() > 0
+	}
+	return !r.IsZero()
+}
+`,
+	},
+
+	"pyIsInstance": {
+		[]string{},
+		[]string{},
+		`func pyIsInstance[T any](v any) bool {
+	_, ok := v.(T)
+	return ok
+}
+`,
+	},
+
+	"pyOrd": {
+		[]string{"strconv", "unicode/utf8"},
+		[]string{"TypeError"},
+		`func pyOrd(s string) (int, error) {
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, &TypeError{"ord() expected a character, but string of length " + strconv.Itoa(utf8.RuneCountInString(s)) + " found"}
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return int(r), nil
+}
+`,
+	},
+
+	"pyBigOrd": {
+		[]string{"math/big"},
+		[]string{"pyOrd"},
+		`func pyBigOrd(s string) (*big.Int, error) {
+	r, err := pyOrd(s)
+	return big.NewInt(int64(r)), err
+}
+`,
+	},
+
+	// Python's input, which reads a line without its newline
+	"pyInput": {
+		[]string{"bufio", "fmt", "io", "os", "strings"},
+		[]string{"EOFError"},
+		`var pyStdin = bufio.NewReader(os.Stdin)
+
+func pyInput(prompt string) (string, error) {
+	fmt.Print(prompt)
+	text, err := pyStdin.ReadString('\n')
+	if err == io.EOF && text == "" {
+		return "", &EOFError{"EOF when reading a line"}
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r"), nil
+}
+`,
+	},
+
+	// Rounds to ndigits decimal places, with halves going to even. Formatting
+	// rounds the exact value of x, as Python does
+	"pyRound": {
+		[]string{"math", "strconv"},
+		[]string{},
+		`func pyRound(x float64, ndigits int) float64 {
+	if ndigits < 0 {
+		scale := math.Pow(10, float64(-ndigits))
+		return math.RoundToEven(x/scale) * scale
+	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(x, 'f', ndigits, 64), 64)
+	return r
+}
+`,
+	},
+
+	// Rounds an int to ndigits, which only changes it when ndigits is
+	// negative, with halves going to even
+	"pyBigRoundInt": {
+		[]string{"math/big"},
+		[]string{},
+		`func pyBigRoundInt(x *big.Int, ndigits int) *big.Int {
+	if ndigits >= 0 {
+		return x
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-ndigits)), nil)
+	q, r := new(big.Int).DivMod(x, scale, new(big.Int))
+	half := new(big.Int).Lsh(r, 1).Cmp(scale)
+	if half > 0 || half == 0 && q.Bit(0) == 1 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q.Mul(q, scale)
+}
+`,
+	},
+
+	"pyRoundInt": {
+		[]string{"math/big"},
+		[]string{"pyBigRoundInt"},
+		`func pyRoundInt[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](x T, ndigits int) T {
+	n := new(big.Int)
+	if x < 0 {
+		n.SetInt64(int64(x))
+	} else {
+		n.SetUint64(uint64(x))
+	}
+	r := pyBigRoundInt(n, ndigits)
+	if r.IsInt64() {
+		return T(r.Int64())
+	}
+	return T(r.Uint64())
+}
+`,
+	},
+
+	// str.split with a separator, which can't be empty
+	"pySplit": {
+		[]string{"strings"},
+		[]string{"ValueError"},
+		`func pySplit(s, sep string) ([]string, error) {
+	if sep == "" {
+		return nil, &ValueError{"empty separator"}
+	}
+	return strings.Split(s, sep), nil
+}
+`,
+	},
+
+	// str.find, which counts characters rather than bytes
+	"pyFind": {
+		[]string{"strings", "unicode/utf8"},
+		[]string{},
+		`func pyFind(s, sub string) int {
+	i := strings.Index(s, sub)
+	if i < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(s[:i])
+}
+`,
+	},
+
+	// str.isdigit and the like, which are false for an empty string
+	"pyStrIs": {
+		[]string{},
+		[]string{},
+		`func pyStrIs(s string, class func(rune) bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !class(r) {
+			return false
+		}
+	}
+	return true
+}
+`,
+	},
+
+	"pyExists": {
+		[]string{"os"},
+		[]string{},
+		`func pyExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+`,
+	},
+
+	// The generator Python's random module uses, which random.seed resets
+	"pyRand": {
+		[]string{"math/rand", "time"},
+		[]string{},
+		`var pyRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+`,
+	},
+
+	"pyRandom": {
+		[]string{},
+		[]string{"pyRand"},
+		`func pyRandom() float64 {
+	return pyRand.Float64()
+}
+`,
+	},
+
+	"pySeed": {
+		[]string{},
+		[]string{"pyRand"},
+		`func pySeed(n int) {
+	pyRand.Seed(int64(n))
+}
+`,
+	},
+
+	// A random int from a to b, including b as Python's does
+	"pyRandint": {
+		[]string{},
+		[]string{"pyRand"},
+		`func pyRandint(a, b int) int {
+	return a + pyRand.Intn(b-a+1)
+}
+`,
+	},
+
+	"pyUniform": {
+		[]string{},
+		[]string{"pyRand"},
+		`func pyUniform(a, b float64) float64 {
+	return a + (b-a)*pyRand.Float64()
+}
+`,
+	},
+
+	"pyChoice": {
+		[]string{},
+		[]string{"pyRand"},
+		`func pyChoice[T any](xs []T) T {
+	return xs[pyRand.Intn(len(xs))]
+}
+`,
+	},
+
+	"pyShuffle": {
+		[]string{},
+		[]string{"pyRand"},
+		`func pyShuffle[T any](xs []T) {
+	pyRand.Shuffle(len(xs), func(i, j int) { xs[i], xs[j] = xs[j], xs[i] })
+}
+`,
+	},
+
+	// Seconds since the epoch, as a float like Python's
+	"pyTime": {
+		[]string{"time"},
+		[]string{},
+		`func pyTime() float64 {
+	return float64(time.Now().UnixNano()) / 1e9
+}
+`,
+	},
+
+	"pySleep": {
+		[]string{"time"},
+		[]string{},
+		`func pySleep(seconds float64) {
+	time.Sleep(time.Duration(seconds * float64(time.Second)))
+}
+`,
+	},
+
+	"pyPrint": {
+		[]string{"fmt", "io", "strings"},
+		[]string{"pyStr"},
+		`func pyPrint(w io.Writer, sep, end string, args ...any) {
+	text := make([]string, len(args))
+	for i := 0; i < len(args); i++ {
+		text[i] = pyStr(args[i])
+	}
+	fmt.Fprint(w, strings.Join(text, sep)+end)
+}
+`,
+	},
+
+	// Formats values the way Python's str() does
+	"pyStr": {
+		[]string{"fmt", "reflect", "sort", "strconv", "strings"},
+		[]string{},
+		`func pyStr(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "None"
+	case string:
+		return t
+	case bool:
+		if t {
+			return "True"
+		}
+		return "False"
+	case float32:
+		return pyFloat(float64(t), 32)
+	case float64:
+		return pyFloat(t, 64)
+	case fmt.Stringer:
+		return t.String()
+	case error:
+		return t.Error()
+	}
+
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, r.Len())
+		for i := 0; i < r.Len(); i++ {
+			items[i] = pyRepr(r.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		// Dicts keep their order, so a map is one from Go, which has no order
+		// to keep. Sort to stay deterministic
+		items := make([]string, 0, r.Len())
+		iter := r.MapRange()
+		for iter.Next() {
+			items = append(items, pyRepr(iter.Key().Interface())+": "+pyRepr(iter.Value().Interface()))
+		}
+		sort.Strings(items)
+		return "{" + strings.Join(items, ", ") + "}"
+	case reflect.Pointer:
+		if r.IsNil() {
+			return "None"
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// Formats values the way Python's repr() does
+func pyRepr(v any) string {
+	s, ok := v.(string)
+	if !ok {
+		return pyStr(v)
+	}
+	quote := "'"
+	if strings.Contains(s, "'") && !strings.Contains(s, "\"") {
+		quote = "\""
+	}
+	s = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r", "\t", "\\t", quote, "\\"+quote).Replace(s)
+	return quote + s + quote
+}
+
+func pyFloat(f float64, size int) string {
+	switch {
+	case f != f:
+		return "nan"
+	case f > 0 && f*0.5 == f:
+		return "inf"
+	case f < 0 && f*0.5 == f:
+		return "-inf"
+	}
+	if f != 0 && (f >= 1e16 || f <= -1e16 || (f < 1e-4 && f > -1e-4)) {
+		return strconv.FormatFloat(f, 'g', -1, size)
+	}
+	s := strconv.FormatFloat(f, 'f', -1, size)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+`,
+	},
+}
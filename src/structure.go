@@ -7,6 +7,7 @@ type Structure struct {
 	text     string
 	line     int
 	children []Structure
+	varType  string // Filled in by the analyzer for values
 }
 
 func createStructure(code, text string, line int) Structure {
@@ -15,6 +16,7 @@ func createStructure(code, text string, line int) Structure {
 		text,
 		line,
 		[]Structure{},
+		"",
 	}
 }
 
@@ -43,6 +45,16 @@ var structureCode map[string]int = map[string]int{
 	"ST_CALL":         9,
 	"ST_FUNCTION":     10,
 	"ST_RETURN":       11,
+	"ST_RAISE":        12,
+	"ST_TRY":          13,
+	"ST_EXCEPT":       14,
+	"ST_WITH":         15,
+	"ST_CLASS":        16,
+	"ST_BREAK":        17,
+	"ST_CONTINUE":     18,
+	"ST_PASS":         19,
+	"ST_NONLOCAL":     20,
+	"ST_GLOBAL":       21,
 
 	// Other
 	"BLOCK":         32,
@@ -69,19 +81,39 @@ var structureCode map[string]int = map[string]int{
 	"ACCESSOR":      53,
 	"FUNC_NAME":     54,
 	"ARROW":         55,
+	"KEYWORD_ARG":   56,
+	"ATTRIBUTE":     57,
+	"PARAMETER":     58,
+	"ST_UNPACK":     59,
+	"WITH_ITEM":     60,
+	"LIST":          61,
+	"DICT":          62,
+	"AUG_ASSIGN":    63,
+	"LAMBDA":        31,
 
 	// Keywords
-	"K_IMPORT": 64,
-	"K_FROM":   65,
-	"K_FOR":    66,
-	"K_IN":     67,
-	"K_IF":     68,
-	"K_ELIF":   69,
-	"K_ELSE":   70,
-	"K_CLASS":  71,
-	"K_WHILE":  72,
-	"K_DEF":    73,
-	"K_RETURN": 74,
+	"K_IMPORT":   64,
+	"K_FROM":     65,
+	"K_FOR":      66,
+	"K_IN":       67,
+	"K_IF":       68,
+	"K_ELIF":     69,
+	"K_ELSE":     70,
+	"K_CLASS":    71,
+	"K_WHILE":    72,
+	"K_DEF":      73,
+	"K_RETURN":   74,
+	"K_RAISE":    75,
+	"K_TRY":      76,
+	"K_EXCEPT":   77,
+	"K_AS":       78,
+	"K_WITH":     79,
+	"K_BREAK":    80,
+	"K_CONTINUE": 81,
+	"K_PASS":     82,
+	"K_LAMBDA":   83,
+	"K_NONLOCAL": 84,
+	"K_GLOBAL":   85,
 
 	// In-built functions
 	"IB_PRINT": 96,
@@ -93,11 +125,13 @@ var structureCode map[string]int = map[string]int{
 	"BO_OR":  130,
 
 	// Math operands
-	"MO_PLUS":   160,
-	"MO_SUB":    161,
-	"MO_MUL":    162,
-	"MO_DIV":    163,
-	"MO_MODULO": 164,
+	"MO_PLUS":      160,
+	"MO_SUB":       161,
+	"MO_MUL":       162,
+	"MO_DIV":       163,
+	"MO_MODULO":    164,
+	"MO_FLOOR_DIV": 165,
+	"MO_POW":       166,
 
 	// Literal
 	"L_BOOL":   192,
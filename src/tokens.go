@@ -11,17 +11,28 @@ var tokenCode map[string]int = map[string]int{
 	"ILLEGAL": -1,
 
 	// Keywords
-	"K_IMPORT": 0,
-	"K_FROM":   1,
-	"K_FOR":    2,
-	"K_IN":     3,
-	"K_IF":     4,
-	"K_ELIF":   5,
-	"K_ELSE":   6,
-	"K_CLASS":  7,
-	"K_WHILE":  8,
-	"K_DEF":    9,
-	"K_RETURN": 10,
+	"K_IMPORT":   0,
+	"K_FROM":     1,
+	"K_FOR":      2,
+	"K_IN":       3,
+	"K_IF":       4,
+	"K_ELIF":     5,
+	"K_ELSE":     6,
+	"K_CLASS":    7,
+	"K_WHILE":    8,
+	"K_DEF":      9,
+	"K_RETURN":   10,
+	"K_RAISE":    11,
+	"K_TRY":      12,
+	"K_EXCEPT":   13,
+	"K_AS":       14,
+	"K_WITH":     15,
+	"K_BREAK":    16,
+	"K_CONTINUE": 17,
+	"K_PASS":     18,
+	"K_LAMBDA":   19,
+	"K_NONLOCAL": 20,
+	"K_GLOBAL":   21,
 
 	// In-Built Funcs
 	"IB_PRINT": 32,
@@ -33,11 +44,13 @@ var tokenCode map[string]int = map[string]int{
 	"BO_OR":  66,
 
 	// Math operands
-	"MO_PLUS":   66,
-	"MO_SUB":    67,
-	"MO_MUL":    68,
-	"MO_DIV":    69,
-	"MO_MODULO": 70,
+	"MO_PLUS":      66,
+	"MO_SUB":       67,
+	"MO_MUL":       68,
+	"MO_DIV":       69,
+	"MO_MODULO":    70,
+	"MO_FLOOR_DIV": 71,
+	"MO_POW":       72,
 
 	// Other
 	"IDENTIFIER":    128,
@@ -59,6 +72,7 @@ var tokenCode map[string]int = map[string]int{
 	"ACCESSOR":      144,
 	"FUNC_NAME":     145,
 	"ARROW":         146,
+	"AUG_ASSIGN":    147,
 
 	// Literals
 	"L_BOOL":   160,
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// How often pogo watch looks at the sources of a project
+const watchInterval = 500 * time.Millisecond
+
+// Rebuilds a project when its sources change, and runs the program after
+// each build that works if run is set
+type Watcher struct {
+	dir      string
+	settings Settings
+	run      bool
+	times    map[string]time.Time // When each source was last changed
+	program  *exec.Cmd            // The program that was started last, if there is one
+	exited   chan bool            // Closed when the program has exited
+}
+
+// Watches a project until Pogo is stopped. Changes are found by polling the
+// modification times of the .py files and pogo.toml, and the cache makes each
+// build compile only the modules that are affected
+func watchProject(dir string, settings Settings, run bool) {
+	w := Watcher{dir: dir, settings: settings, run: run}
+	for {
+		times := sourceTimes(dir)
+		if !sameTimes(w.times, times) {
+			w.times = times
+			w.build()
+		}
+		time.Sleep(watchInterval)
+	}
+}
+
+// The modification time of each source of a project, in the project and the
+// source directories of its manifest, which can be outside it. The output
+// directory, and hidden ones such as the cache, aren't looked in
+func sourceTimes(dir string) map[string]time.Time {
+	roots := []string{dir}
+	skip := map[string]bool{}
+	if manifest, err := readManifest(filepath.Join(dir, "pogo.toml")); err == nil {
+		for i := 0; i < len(manifest.sources); i++ {
+			roots = append(roots, filepath.Join(dir, manifest.sources[i]))
+		}
+		skip[filepath.Join(dir, manifest.output)] = true
+	}
+
+	times := map[string]time.Time{}
+	for i := 0; i < len(roots); i++ {
+		root := roots[i]
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if skip[path] || (path != root && strings.HasPrefix(entry.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				// A source directory in the project is only looked in once
+				skip[path] = true
+				return nil
+			}
+			if strings.HasSuffix(path, ".py") || entry.Name() == "pogo.toml" {
+				if info, err := entry.Info(); err == nil {
+					times[path] = info.ModTime()
+				}
+			}
+			return nil
+		})
+	}
+	return times
+}
+
+func sameTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, t := range a {
+		if other, exists := b[path]; !exists || !other.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Builds the project, printing what was compiled or what is wrong with it. A
+// program left running from the last build is stopped first
+func (w *Watcher) build() {
+	w.stop()
+	start := time.Now()
+	compiled, err := w.buildProject()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if len(compiled) == 0 {
+		log.Println("Nothing to compile")
+	} else {
+		log.Println("Compiled " + strings.Join(compiled, ", ") + " in " + time.Since(start).Round(time.Millisecond).String())
+	}
+	if w.run {
+		w.start()
+	}
+}
+
+// Builds the project, reporting a panic in the compiler as an error, so one
+// bad edit doesn't stop the watcher
+func (w *Watcher) buildProject() (compiled []string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = createError([]string{"watch.go", "buildProject"}, fmt.Sprint("Pogo failed: ", r), 0)
+		}
+	}()
+	return buildProject(w.dir, w.settings, false)
+}
+
+// Builds the Go module to a binary in the cache directory and starts it, so it
+// can be stopped when the sources change again
+func (w *Watcher) start() {
+	manifest, err := readManifest(filepath.Join(w.dir, "pogo.toml"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	binary, err := filepath.Abs(filepath.Join(w.dir, ".pogo-cache", "program"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = filepath.Join(w.dir, manifest.output)
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if build.Run() != nil {
+		log.Println("The Go module didn't build")
+		return
+	}
+
+	program := exec.Command(binary)
+	program.Stdin = os.Stdin
+	program.Stdout = os.Stdout
+	program.Stderr = os.Stderr
+	err = program.Start()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	w.program = program
+	w.exited = make(chan bool)
+	go func(exited chan bool) {
+		err := program.Wait()
+		if err != nil && program.ProcessState != nil && program.ProcessState.Exited() {
+			log.Println("The program exited with status", program.ProcessState.ExitCode())
+		}
+		close(exited)
+	}(w.exited)
+}
+
+// Stops the program if it is still running
+func (w *Watcher) stop() {
+	if w.program == nil {
+		return
+	}
+	select {
+	case <-w.exited:
+	default:
+		w.program.Process.Kill()
+		<-w.exited
+	}
+	w.program = nil
+}